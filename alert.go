@@ -2,15 +2,27 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os/exec"
+	"strings"
 	"text/template"
 	"time"
 
 	"git.iamthefij.com/iamthefij/slog"
 )
 
+const (
+	// defaultNoticeTitle is the title used by built in alert types when none is configured
+	defaultNoticeTitle = "{{.MonitorName}} is {{if .IsUp}}up{{else}}down{{end}}"
+	// defaultNoticeMessage is the message used by built in alert types when none is configured
+	defaultNoticeMessage = "{{.MonitorName}} is {{if .IsUp}}up{{else}}down{{end}} " +
+		"after {{.FailureCount}} failures. Last success was {{RFC1123 .LastSuccess}}.\n\n{{.LastCheckOutput}}"
+	// defaultNoticeKey identifies the incident for a monitor in alert types that resolve on recovery
+	defaultNoticeKey = "minitor/{{.MonitorName}}"
+)
+
 var (
 	errNoTemplate = errors.New("no template")
 
@@ -23,40 +35,162 @@ type Alert struct {
 	Name                 string   `hcl:"name,label"`
 	Command              []string `hcl:"command,optional"`
 	ShellCommand         string   `hcl:"shell_command,optional"`
+	GroupWaitStr         *string  `hcl:"group_wait,optional"`
+	GroupIntervalStr     *string  `hcl:"group_interval,optional"`
+	GroupWait            time.Duration
+	GroupInterval        time.Duration
+	RateLimit            *RateLimit `hcl:"rate_limit,block"`
 	commandTemplate      []*template.Template
 	commandShellTemplate *template.Template
+
+	// Built in alert types
+	Webhook      *WebhookAlert      `hcl:"webhook,block"`
+	Email        *EmailAlert        `hcl:"email,block"`
+	Slack        *SlackAlert        `hcl:"slack,block"`
+	Discord      *DiscordAlert      `hcl:"discord,block"`
+	Ntfy         *NtfyAlert         `hcl:"ntfy,block"`
+	Gotify       *GotifyAlert       `hcl:"gotify,block"`
+	PagerDuty    *PagerDutyAlert    `hcl:"pagerduty,block"`
+	Opsgenie     *OpsgenieAlert     `hcl:"opsgenie,block"`
+	Telegram     *TelegramAlert     `hcl:"telegram,block"`
+	Matrix       *MatrixAlert       `hcl:"matrix,block"`
+	Alertmanager *AlertmanagerAlert `hcl:"alertmanager,block"`
+
+	// Grouping state
+	pendingNotices []AlertNotice
+	groupStart     time.Time
+	lastGroupSent  time.Time
 }
 
 // AlertNotice captures the context for an alert to be sent
 type AlertNotice struct {
 	AlertCount      int
 	FailureCount    int
+	SuccessCount    int
 	IsUp            bool
+	IsFlapping      bool
 	LastSuccess     time.Time
+	FailingSince    time.Time
 	MonitorName     string
 	LastCheckOutput string
+	FailedTargets   []string
+	Notices         []AlertNotice
+	SuppressedCount int
+}
+
+// alertSender is implemented by each built in alert type
+type alertSender interface {
+	Validate() error
+	BuildTemplates(name string, funcs template.FuncMap) error
+	Send(notice AlertNotice) (string, error)
+}
+
+// alertResender is implemented by built in alert types that repeat notices while monitors are down.
+// The names of monitors that are up are given so that alerts whose recovery was never sent can be resolved
+type alertResender interface {
+	Resend(now time.Time, upMonitors map[string]bool) (string, error)
+}
+
+// senders returns the built in alert types configured for the Alert
+func (alert Alert) senders() []alertSender {
+	senders := []alertSender{}
+
+	if alert.Webhook != nil {
+		senders = append(senders, alert.Webhook)
+	}
+
+	if alert.Email != nil {
+		senders = append(senders, alert.Email)
+	}
+
+	if alert.Slack != nil {
+		senders = append(senders, alert.Slack)
+	}
+
+	if alert.Discord != nil {
+		senders = append(senders, alert.Discord)
+	}
+
+	if alert.Ntfy != nil {
+		senders = append(senders, alert.Ntfy)
+	}
+
+	if alert.Gotify != nil {
+		senders = append(senders, alert.Gotify)
+	}
+
+	if alert.PagerDuty != nil {
+		senders = append(senders, alert.PagerDuty)
+	}
+
+	if alert.Opsgenie != nil {
+		senders = append(senders, alert.Opsgenie)
+	}
+
+	if alert.Telegram != nil {
+		senders = append(senders, alert.Telegram)
+	}
+
+	if alert.Matrix != nil {
+		senders = append(senders, alert.Matrix)
+	}
+
+	if alert.Alertmanager != nil {
+		senders = append(senders, alert.Alertmanager)
+	}
+
+	return senders
 }
 
 // Validate checks that the Alert is properly configured and returns errors if not
 func (alert Alert) Validate() error {
 	hasCommand := len(alert.Command) > 0
 	hasShellCommand := alert.ShellCommand != ""
+	senders := alert.senders()
 
 	var err error
 
-	hasAtLeastOneCommand := hasCommand || hasShellCommand
+	hasAtLeastOneCommand := hasCommand || hasShellCommand || len(senders) > 0
 	if !hasAtLeastOneCommand {
 		err = errors.Join(err, fmt.Errorf(
-			"%w: alert %s has no command or shell_command configured",
+			"%w: alert %s has no command, shell_command or alert type block configured",
 			ErrInvalidAlert,
 			alert.Name,
 		))
 	}
 
-	hasAtMostOneCommand := !(hasCommand && hasShellCommand)
+	for _, sender := range senders {
+		if senderErr := sender.Validate(); senderErr != nil {
+			err = errors.Join(err, fmt.Errorf("%w: alert %s. %w", ErrInvalidAlert, alert.Name, senderErr))
+		}
+	}
+
+	if alert.GroupWait < 0 || alert.GroupInterval < 0 {
+		err = errors.Join(err, fmt.Errorf(
+			"%w: alert %s has a negative group_wait or group_interval",
+			ErrInvalidAlert,
+			alert.Name,
+		))
+	}
+
+	if alert.RateLimit != nil {
+		if limitErr := alert.RateLimit.Validate(); limitErr != nil {
+			err = errors.Join(err, fmt.Errorf("%w: alert %s. %w", ErrInvalidAlert, alert.Name, limitErr))
+		}
+	}
+
+	commandCount := len(senders)
+
+	for _, isConfigured := range []bool{hasCommand, hasShellCommand} {
+		if isConfigured {
+			commandCount++
+		}
+	}
+
+	hasAtMostOneCommand := commandCount <= 1
 	if !hasAtMostOneCommand {
 		err = errors.Join(err, fmt.Errorf(
-			"%w: alert %s has both command and shell_command configured",
+			"%w: alert %s has more than one of command, shell_command or alert type block configured",
 			ErrInvalidAlert,
 			alert.Name,
 		))
@@ -65,10 +199,41 @@ func (alert Alert) Validate() error {
 	return err
 }
 
-// BuildTemplates compiles command templates for the Alert
-func (alert *Alert) BuildTemplates() error {
-	slog.Debugf("Building template for alert %s", alert.Name)
+// Init parses the grouping durations of the Alert
+func (alert *Alert) Init() error {
+	var err error
+
+	if alert.GroupWaitStr != nil {
+		alert.GroupWait, err = time.ParseDuration(*alert.GroupWaitStr)
+		if err != nil {
+			return fmt.Errorf("failed to parse group_wait duration for alert %s: %w", alert.Name, err)
+		}
+	}
+
+	if alert.GroupIntervalStr != nil {
+		alert.GroupInterval, err = time.ParseDuration(*alert.GroupIntervalStr)
+		if err != nil {
+			return fmt.Errorf("failed to parse group_interval duration for alert %s: %w", alert.Name, err)
+		}
+	}
 
+	if alert.RateLimit != nil {
+		if err = alert.RateLimit.Init(); err != nil {
+			return fmt.Errorf("failed to initialize rate limit for alert %s: %w", alert.Name, err)
+		}
+	}
+
+	if alert.Alertmanager != nil {
+		if err = alert.Alertmanager.Init(); err != nil {
+			return fmt.Errorf("failed to initialize alertmanager for alert %s: %w", alert.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// templateFuncs returns the non-standard functions available in alert templates
+func templateFuncs() template.FuncMap {
 	// Time format func factory
 	tff := func(formatString string) func(time.Time) string {
 		return func(t time.Time) string {
@@ -98,9 +263,31 @@ func (alert *Alert) BuildTemplates() error {
 
 			return t.In(tz), nil
 		},
+		"toJson": func(v any) (string, error) {
+			content, err := json.Marshal(v)
+			if err != nil {
+				return "", fmt.Errorf("failed to encode value as json: %w", err)
+			}
+
+			return string(content), nil
+		},
 	}
 
+	return timeFormatFuncs
+}
+
+// BuildTemplates compiles command templates for the Alert
+func (alert *Alert) BuildTemplates() error {
+	slog.Debugf("Building template for alert %s", alert.Name)
+
+	timeFormatFuncs := templateFuncs()
+	senders := alert.senders()
+
 	switch {
+	case len(senders) > 0:
+		if err := senders[0].BuildTemplates(alert.Name, timeFormatFuncs); err != nil {
+			return fmt.Errorf("failed to build templates for alert %s: %w", alert.Name, err)
+		}
 	case alert.Command != nil:
 		alert.commandTemplate = []*template.Template{}
 		for i, cmdPart := range alert.Command {
@@ -125,6 +312,17 @@ func (alert *Alert) BuildTemplates() error {
 func (alert Alert) Send(notice AlertNotice) (outputStr string, err error) {
 	slog.Infof("Sending alert %s for %s", alert.Name, notice.MonitorName)
 
+	if senders := alert.senders(); len(senders) > 0 {
+		outputStr, err = senders[0].Send(notice)
+		slog.Debugf("Alert output for: %s\n---\n%s\n---", alert.Name, outputStr)
+
+		if err != nil {
+			err = fmt.Errorf("Alert %s failed to send: %w", alert.Name, err)
+		}
+
+		return outputStr, err
+	}
+
 	var cmd *exec.Cmd
 
 	switch {
@@ -181,3 +379,172 @@ func (alert Alert) Send(notice AlertNotice) (outputStr string, err error) {
 
 	return outputStr, err
 }
+
+// Resend repeats notices for alert types that need them while monitors are down
+func (alert Alert) Resend(now time.Time, upMonitors map[string]bool) (string, error) {
+	for _, sender := range alert.senders() {
+		resender, ok := sender.(alertResender)
+		if !ok {
+			continue
+		}
+
+		output, err := resender.Resend(now, upMonitors)
+		if err != nil {
+			err = fmt.Errorf("Alert %s failed to resend: %w", alert.Name, err)
+		}
+
+		return output, err
+	}
+
+	return "", nil
+}
+
+// IsGrouped returns true if notices for the Alert are batched before being sent
+func (alert Alert) IsGrouped() bool {
+	return alert.GroupWait > 0 || alert.GroupInterval > 0
+}
+
+// Queue adds a notice to the group waiting to be sent
+func (alert *Alert) Queue(notice AlertNotice, now time.Time) {
+	if len(alert.pendingNotices) == 0 {
+		alert.groupStart = now
+	}
+
+	alert.pendingNotices = append(alert.pendingNotices, notice)
+}
+
+// IsGroupDue returns true if there are queued notices and both the group wait and interval have passed
+func (alert Alert) IsGroupDue(now time.Time) bool {
+	return len(alert.pendingNotices) > 0 &&
+		!now.Before(alert.groupStart.Add(alert.GroupWait)) &&
+		!now.Before(alert.lastGroupSent.Add(alert.GroupInterval))
+}
+
+// TakeGroup removes and returns all queued notices, starting a new group interval
+func (alert *Alert) TakeGroup(now time.Time) []AlertNotice {
+	notices := alert.pendingNotices
+	alert.pendingNotices = nil
+	alert.lastGroupSent = now
+
+	return notices
+}
+
+// SendGroup sends a group of notices. A single notice is sent as is, while multiple notices
+// are sent as one digest notice with each listed in Notices
+func (alert Alert) SendGroup(notices []AlertNotice) (string, error) {
+	if len(notices) == 1 {
+		return alert.Send(notices[0])
+	}
+
+	return alert.Send(NewDigestNotice(notices))
+}
+
+// renderTemplate executes a template with the notice and returns the result
+func renderTemplate(tmpl *template.Template, notice AlertNotice) (string, error) {
+	var rendered strings.Builder
+
+	if err := tmpl.Execute(&rendered, notice); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", tmpl.Name(), err)
+	}
+
+	return rendered.String(), nil
+}
+
+// formatLastSuccess formats the time of the last success, or Never if there has not been one
+func formatLastSuccess(lastSuccess time.Time) string {
+	if lastSuccess.IsZero() {
+		return "Never"
+	}
+
+	return lastSuccess.Format(time.RFC1123)
+}
+
+// noticeDetails returns the details of a notice as strings for alert types that accept key value details
+func noticeDetails(notice AlertNotice) map[string]string {
+	details := map[string]string{
+		"monitor":           notice.MonitorName,
+		"failure_count":     fmt.Sprint(notice.FailureCount),
+		"alert_count":       fmt.Sprint(notice.AlertCount),
+		"last_success":      formatLastSuccess(notice.LastSuccess),
+		"last_check_output": notice.LastCheckOutput,
+	}
+
+	if len(notice.FailedTargets) > 0 {
+		details["failed_targets"] = strings.Join(notice.FailedTargets, ", ")
+	}
+
+	return details
+}
+
+// noticePriority returns the configured priority for the state of the notice, falling back to defaults
+func noticePriority(notice AlertNotice, down, up *int, defaultDown, defaultUp int) int {
+	if notice.IsUp {
+		if up != nil {
+			return *up
+		}
+
+		return defaultUp
+	}
+
+	if down != nil {
+		return *down
+	}
+
+	return defaultDown
+}
+
+// validatePriority returns an error wrapping errType if a configured priority is outside of the range
+func validatePriority(errType error, key string, priority *int, minPriority, maxPriority int) error {
+	if priority != nil && (*priority < minPriority || *priority > maxPriority) {
+		return fmt.Errorf("%w: %s must be between %d and %d", errType, key, minPriority, maxPriority)
+	}
+
+	return nil
+}
+
+// truncateText shortens text to at most maxLength characters, marking where it was cut
+func truncateText(text string, maxLength int) string {
+	runes := []rune(text)
+	if maxLength <= 0 || len(runes) <= maxLength {
+		return text
+	}
+
+	return string(runes[:maxLength-1]) + "…"
+}
+
+// NewDigestNotice combines several notices into a single notice listing each of them in Notices
+func NewDigestNotice(notices []AlertNotice) AlertNotice {
+	monitorNames := []string{}
+	digest := AlertNotice{IsUp: true, Notices: notices}
+
+	for _, notice := range notices {
+		monitorNames = append(monitorNames, notice.MonitorName)
+		digest.IsUp = digest.IsUp && notice.IsUp
+		digest.IsFlapping = digest.IsFlapping || notice.IsFlapping
+	}
+
+	digest.MonitorName = strings.Join(monitorNames, ", ")
+
+	return digest
+}
+
+// sendEachNotice sends each notice of a digest separately, or the notice itself if it is not a
+// digest. Every notice is sent even if some fail
+func sendEachNotice(notice AlertNotice, send func(AlertNotice) (string, error)) (string, error) {
+	notices := notice.Notices
+	if len(notices) == 0 {
+		notices = []AlertNotice{notice}
+	}
+
+	outputs := []string{}
+
+	var err error
+
+	for _, notice := range notices {
+		output, sendErr := send(notice)
+		outputs = append(outputs, output)
+		err = errors.Join(err, sendErr)
+	}
+
+	return strings.Join(outputs, "\n"), err
+}
@@ -3,6 +3,7 @@ package main_test
 import (
 	"errors"
 	"testing"
+	"time"
 
 	m "git.iamthefij.com/iamthefij/minitor-go/v2"
 )
@@ -19,6 +20,21 @@ func TestAlertValidate(t *testing.T) {
 		{m.Alert{ShellCommand: "echo test"}, nil, "CommandShell only"},
 		{m.Alert{Command: []string{"echo", "test"}, ShellCommand: "echo test"}, m.ErrInvalidAlert, "Both commands"},
 		{m.Alert{}, m.ErrInvalidAlert, "No commands"},
+		{m.Alert{Webhook: &m.WebhookAlert{URL: "https://example.com"}}, nil, "Webhook only"},
+		{m.Alert{ShellCommand: "echo test", Webhook: &m.WebhookAlert{URL: "https://example.com"}}, m.ErrInvalidAlert, "Command and webhook"},
+		{m.Alert{Webhook: &m.WebhookAlert{}}, m.ErrInvalidAlert, "Invalid webhook"},
+		{m.Alert{Email: &m.EmailAlert{}}, m.ErrInvalidAlert, "Invalid email"},
+		{m.Alert{Slack: &m.SlackAlert{}}, m.ErrInvalidAlert, "Invalid slack"},
+		{m.Alert{Discord: &m.DiscordAlert{}}, m.ErrInvalidAlert, "Invalid discord"},
+		{m.Alert{Ntfy: &m.NtfyAlert{}}, m.ErrInvalidAlert, "Invalid ntfy"},
+		{m.Alert{Gotify: &m.GotifyAlert{}}, m.ErrInvalidAlert, "Invalid gotify"},
+		{m.Alert{PagerDuty: &m.PagerDutyAlert{}}, m.ErrInvalidAlert, "Invalid pagerduty"},
+		{m.Alert{Opsgenie: &m.OpsgenieAlert{}}, m.ErrInvalidAlert, "Invalid opsgenie"},
+		{m.Alert{Telegram: &m.TelegramAlert{}}, m.ErrInvalidAlert, "Invalid telegram"},
+		{m.Alert{Matrix: &m.MatrixAlert{}}, m.ErrInvalidAlert, "Invalid matrix"},
+		{m.Alert{Alertmanager: &m.AlertmanagerAlert{}}, m.ErrInvalidAlert, "Invalid alertmanager"},
+		{m.Alert{ShellCommand: "echo test", GroupWait: -time.Second}, m.ErrInvalidAlert, "Negative group_wait"},
+		{m.Alert{ShellCommand: "echo test", RateLimit: &m.RateLimit{Count: 0, Period: time.Hour}}, m.ErrInvalidAlert, "Invalid rate limit"},
 	}
 
 	for _, c := range cases {
@@ -138,3 +154,74 @@ func TestAlertBuildTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestAlertGroup(t *testing.T) {
+	t.Parallel()
+
+	alert := m.Alert{
+		Name:             "digest",
+		ShellCommand:     "echo '{{if .Notices}}{{range .Notices}}{{.MonitorName}};{{end}}{{else}}single {{.MonitorName}}{{end}}'",
+		GroupWaitStr:     Ptr("30s"),
+		GroupIntervalStr: Ptr("5m"),
+	}
+
+	if err := alert.Init(); err != nil {
+		t.Fatalf("Init(), unexpected error: %v", err)
+	}
+
+	if err := alert.BuildTemplates(); err != nil {
+		t.Fatalf("BuildTemplates(), unexpected error: %v", err)
+	}
+
+	if !alert.IsGrouped() {
+		t.Fatal("IsGrouped(), expected alert with group_wait to be grouped")
+	}
+
+	start := time.Now()
+
+	if alert.IsGroupDue(start) {
+		t.Error("IsGroupDue(), expected no group to be due without notices")
+	}
+
+	alert.Queue(m.AlertNotice{MonitorName: "a"}, start)
+	alert.Queue(m.AlertNotice{MonitorName: "b"}, start.Add(10*time.Second))
+
+	if alert.IsGroupDue(start.Add(29 * time.Second)) {
+		t.Error("IsGroupDue(), expected group not to be due before group_wait")
+	}
+
+	if !alert.IsGroupDue(start.Add(30 * time.Second)) {
+		t.Fatal("IsGroupDue(), expected group to be due after group_wait")
+	}
+
+	notices := alert.TakeGroup(start.Add(30 * time.Second))
+
+	output, err := alert.SendGroup(notices)
+	if err != nil {
+		t.Fatalf("SendGroup(), unexpected error: %v", err)
+	}
+
+	if len(notices) != 2 || output != "a;b;\n" {
+		t.Errorf("SendGroup(), expected digest of 2 notices, got notices=%v output=%q", notices, output)
+	}
+
+	// A notice arriving alone is sent as a normal notice once the interval has passed
+	alert.Queue(m.AlertNotice{MonitorName: "c"}, start.Add(time.Minute))
+
+	if alert.IsGroupDue(start.Add(2 * time.Minute)) {
+		t.Error("IsGroupDue(), expected group not to be due before group_interval")
+	}
+
+	if !alert.IsGroupDue(start.Add(330 * time.Second)) {
+		t.Fatal("IsGroupDue(), expected group to be due after group_interval")
+	}
+
+	output, err = alert.SendGroup(alert.TakeGroup(start.Add(330 * time.Second)))
+	if err != nil {
+		t.Fatalf("SendGroup(), unexpected error: %v", err)
+	}
+
+	if output != "single c\n" {
+		t.Errorf("SendGroup(), expected single notice, got output=%q", output)
+	}
+}
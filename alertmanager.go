@@ -0,0 +1,246 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ErrInvalidAlertmanager indicates that an alertmanager alert is not properly configured
+var ErrInvalidAlertmanager = errors.New("Invalid alertmanager configuration")
+
+const (
+	defaultAlertmanagerAlertName      = "MinitorMonitorDown"
+	defaultAlertmanagerResendInterval = time.Minute
+
+	// alertmanagerEndsAtMultiplier sets how many resend intervals a firing alert is valid for, so
+	// Alertmanager resolves it if minitor stops sending
+	alertmanagerEndsAtMultiplier = 4
+	// alertmanagerMonitorLabel is the label identifying the monitor of an alert
+	alertmanagerMonitorLabel = "monitor"
+)
+
+// alertmanagerLabelName matches valid Prometheus label names
+var alertmanagerLabelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// AlertmanagerAlert is a built in alert type that pushes alerts into a Prometheus Alertmanager
+type AlertmanagerAlert struct {
+	URL               string            `hcl:"url"`
+	Headers           map[string]string `hcl:"headers,optional"`
+	Labels            map[string]string `hcl:"labels,optional"`
+	Annotations       map[string]string `hcl:"annotations,optional"`
+	GeneratorURL      string            `hcl:"generator_url,optional"`
+	ResendIntervalStr *string           `hcl:"resend_interval,optional"`
+	ResendInterval    time.Duration
+
+	annotationTemplates  map[string]*template.Template
+	generatorURLTemplate *template.Template
+
+	// firing holds the alerts of monitors that are down so they can be resent
+	firing map[string]*alertmanagerFiring
+}
+
+// alertmanagerFiring is a firing alert and the last time it was sent
+type alertmanagerFiring struct {
+	alert    postableAlert
+	lastSent time.Time
+}
+
+// postableAlert is an alert accepted by the Alertmanager v2 API
+type postableAlert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     string            `json:"startsAt"`     //nolint:tagliatelle
+	EndsAt       string            `json:"endsAt"`       //nolint:tagliatelle
+	GeneratorURL string            `json:"generatorURL"` //nolint:tagliatelle
+}
+
+// Init parses the resend interval of the AlertmanagerAlert
+func (alertmanager *AlertmanagerAlert) Init() error {
+	alertmanager.ResendInterval = defaultAlertmanagerResendInterval
+
+	if alertmanager.ResendIntervalStr != nil {
+		var err error
+
+		alertmanager.ResendInterval, err = time.ParseDuration(*alertmanager.ResendIntervalStr)
+		if err != nil {
+			return fmt.Errorf("failed to parse alertmanager resend_interval: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks that the AlertmanagerAlert is properly configured and returns errors if not
+func (alertmanager AlertmanagerAlert) Validate() error {
+	var err error
+
+	err = errors.Join(err, validateHTTPURL(ErrInvalidAlertmanager, "url", alertmanager.URL))
+
+	if alertmanager.ResendInterval <= 0 {
+		err = errors.Join(err, fmt.Errorf("%w: resend_interval must be greater than 0", ErrInvalidAlertmanager))
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(alertmanager.Labels)) {
+		switch {
+		case name == alertmanagerMonitorLabel:
+			err = errors.Join(err, fmt.Errorf(
+				"%w: label %s is set from the monitor name and cannot be configured",
+				ErrInvalidAlertmanager,
+				name,
+			))
+		case !alertmanagerLabelName.MatchString(name):
+			err = errors.Join(err, fmt.Errorf("%w: invalid label name %q", ErrInvalidAlertmanager, name))
+		}
+	}
+
+	return err
+}
+
+// BuildTemplates compiles the annotation and generator url templates for the AlertmanagerAlert
+func (alertmanager *AlertmanagerAlert) BuildTemplates(name string, funcs template.FuncMap) error {
+	var err error
+
+	annotations := map[string]string{"summary": defaultNoticeTitle, "description": defaultNoticeMessage}
+	maps.Copy(annotations, alertmanager.Annotations)
+
+	alertmanager.annotationTemplates = map[string]*template.Template{}
+
+	for key, value := range annotations {
+		alertmanager.annotationTemplates[key], err = template.New(name + "-" + key).Funcs(funcs).Parse(value)
+		if err != nil {
+			return err
+		}
+	}
+
+	alertmanager.generatorURLTemplate, err = template.New(name + "-generator_url").Funcs(funcs).Parse(alertmanager.GeneratorURL)
+
+	return err
+}
+
+// Send pushes a firing alert for down notices and resolves it when the monitor recovers. Digests
+// of grouped notices are sent as one alert per monitor
+func (alertmanager *AlertmanagerAlert) Send(notice AlertNotice) (string, error) {
+	if alertmanager.annotationTemplates == nil || alertmanager.generatorURLTemplate == nil {
+		return "", fmt.Errorf("No templates compiled for alertmanager: %w", errNoTemplate)
+	}
+
+	if alertmanager.firing == nil {
+		alertmanager.firing = map[string]*alertmanagerFiring{}
+	}
+
+	notices := notice.Notices
+	if len(notices) == 0 {
+		notices = []AlertNotice{notice}
+	}
+
+	now := time.Now()
+	alerts := []postableAlert{}
+
+	for _, notice := range notices {
+		alert, err := alertmanager.buildAlert(notice, now)
+		if err != nil {
+			return "", err
+		}
+
+		if notice.IsUp {
+			delete(alertmanager.firing, notice.MonitorName)
+		} else {
+			alertmanager.firing[notice.MonitorName] = &alertmanagerFiring{alert: alert, lastSent: now}
+		}
+
+		alerts = append(alerts, alert)
+	}
+
+	return alertmanager.post(alerts)
+}
+
+// Resend pushes the firing alerts that have not been sent within the resend interval so that
+// Alertmanager does not resolve them while the monitors are still down. Alerts of monitors that
+// are up are resolved, as their recovery may have been suppressed before reaching this alert
+func (alertmanager *AlertmanagerAlert) Resend(now time.Time, upMonitors map[string]bool) (string, error) {
+	alerts := []postableAlert{}
+
+	for _, monitorName := range slices.Sorted(maps.Keys(alertmanager.firing)) {
+		firing := alertmanager.firing[monitorName]
+
+		if upMonitors[monitorName] {
+			firing.alert.EndsAt = now.UTC().Format(time.RFC3339)
+			alerts = append(alerts, firing.alert)
+
+			delete(alertmanager.firing, monitorName)
+
+			continue
+		}
+
+		if now.Sub(firing.lastSent) < alertmanager.ResendInterval {
+			continue
+		}
+
+		firing.alert.EndsAt = alertmanager.firingEndsAt(now)
+		firing.lastSent = now
+		alerts = append(alerts, firing.alert)
+	}
+
+	if len(alerts) == 0 {
+		return "", nil
+	}
+
+	return alertmanager.post(alerts)
+}
+
+// buildAlert renders the labels and annotations for a notice. Firing alerts keep the start time
+// of the first notice sent for the monitor
+func (alertmanager AlertmanagerAlert) buildAlert(notice AlertNotice, now time.Time) (postableAlert, error) {
+	labels := map[string]string{"alertname": defaultAlertmanagerAlertName}
+	maps.Copy(labels, alertmanager.Labels)
+	labels[alertmanagerMonitorLabel] = notice.MonitorName
+
+	annotations := map[string]string{}
+
+	for key, tmpl := range alertmanager.annotationTemplates {
+		var err error
+
+		annotations[key], err = renderTemplate(tmpl, notice)
+		if err != nil {
+			return postableAlert{}, err
+		}
+	}
+
+	generatorURL, err := renderTemplate(alertmanager.generatorURLTemplate, notice)
+	if err != nil {
+		return postableAlert{}, err
+	}
+
+	startsAt := now.UTC().Format(time.RFC3339)
+	if firing, ok := alertmanager.firing[notice.MonitorName]; ok {
+		startsAt = firing.alert.StartsAt
+	}
+
+	endsAt := now.UTC().Format(time.RFC3339)
+	if !notice.IsUp {
+		endsAt = alertmanager.firingEndsAt(now)
+	}
+
+	return postableAlert{
+		Labels:       labels,
+		Annotations:  annotations,
+		StartsAt:     startsAt,
+		EndsAt:       endsAt,
+		GeneratorURL: strings.TrimSpace(generatorURL),
+	}, nil
+}
+
+// firingEndsAt returns when a firing alert sent now should be resolved if it is not sent again
+func (alertmanager AlertmanagerAlert) firingEndsAt(now time.Time) string {
+	return now.Add(alertmanagerEndsAtMultiplier * alertmanager.ResendInterval).UTC().Format(time.RFC3339)
+}
+
+func (alertmanager AlertmanagerAlert) post(alerts []postableAlert) (string, error) {
+	return postJSON(strings.TrimSuffix(alertmanager.URL, "/")+"/api/v2/alerts", alertmanager.Headers, alerts)
+}
@@ -0,0 +1,254 @@
+package main_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	m "git.iamthefij.com/iamthefij/minitor-go/v2"
+)
+
+type alertmanagerAlert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"` //nolint:tagliatelle
+	EndsAt      time.Time         `json:"endsAt"`   //nolint:tagliatelle
+}
+
+// decodeAlertmanagerAlerts decodes the alerts posted in a request
+func decodeAlertmanagerAlerts(t *testing.T, request webhookRequest) []alertmanagerAlert {
+	t.Helper()
+
+	alerts := []alertmanagerAlert{}
+	if err := json.Unmarshal([]byte(request.body), &alerts); err != nil {
+		t.Fatalf("invalid json body %q: %v", request.body, err)
+	}
+
+	return alerts
+}
+
+func TestAlertmanagerAlertInit(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		alertmanager m.AlertmanagerAlert
+		expected     time.Duration
+		expectErr    bool
+		name         string
+	}{
+		{m.AlertmanagerAlert{}, time.Minute, false, "Default resend interval"},
+		{m.AlertmanagerAlert{ResendIntervalStr: Ptr("30s")}, 30 * time.Second, false, "Configured resend interval"},
+		{m.AlertmanagerAlert{ResendIntervalStr: Ptr("soon")}, 0, true, "Invalid resend interval"},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := c.alertmanager.Init()
+			hasErr := (err != nil)
+
+			if hasErr != c.expectErr {
+				t.Errorf("Init(%v), expected error=%v actual=%v", c.name, c.expectErr, err)
+			}
+
+			if !c.expectErr && c.alertmanager.ResendInterval != c.expected {
+				t.Errorf("Init(%v), expected=%v actual=%v", c.name, c.expected, c.alertmanager.ResendInterval)
+			}
+		})
+	}
+}
+
+func TestAlertmanagerAlertValidate(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		alertmanager m.AlertmanagerAlert
+		expected     error
+		name         string
+	}{
+		{m.AlertmanagerAlert{URL: "http://alertmanager:9093", ResendInterval: time.Minute}, nil, "Valid"},
+		{m.AlertmanagerAlert{URL: "http://alertmanager:9093", ResendInterval: time.Minute, Labels: map[string]string{"severity": "page", "alertname": "Down"}}, nil, "Valid labels"},
+		{m.AlertmanagerAlert{ResendInterval: time.Minute}, m.ErrInvalidAlertmanager, "No url"},
+		{m.AlertmanagerAlert{URL: "http://alertmanager:9093"}, m.ErrInvalidAlertmanager, "No resend interval"},
+		{m.AlertmanagerAlert{URL: "http://alertmanager:9093", ResendInterval: time.Minute, Labels: map[string]string{"monitor": "web"}}, m.ErrInvalidAlertmanager, "Monitor label"},
+		{m.AlertmanagerAlert{URL: "http://alertmanager:9093", ResendInterval: time.Minute, Labels: map[string]string{"team-name": "ops"}}, m.ErrInvalidAlertmanager, "Invalid label name"},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := c.alertmanager.Validate()
+			hasErr := (actual != nil)
+			expectErr := (c.expected != nil)
+
+			if hasErr != expectErr || !errors.Is(actual, c.expected) {
+				t.Errorf("Validate(%v), expected=%v actual=%v", c.name, c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestAlertmanagerAlertSendAndResend(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newWebhookServer(t, http.StatusOK)
+	alert := &m.Alert{Name: "alertmanager", Alertmanager: &m.AlertmanagerAlert{
+		URL:         server.URL,
+		Labels:      map[string]string{"severity": "page"},
+		Annotations: map[string]string{"summary": "{{.MonitorName}} failed", "runbook_url": "https://wiki/{{.MonitorName}}"},
+	}}
+	config := m.Config{Alerts: []*m.Alert{alert}}
+
+	if err := alert.Init(); err != nil {
+		t.Fatalf("Init(), unexpected error: %v", err)
+	}
+
+	if err := alert.BuildTemplates(); err != nil {
+		t.Fatalf("BuildTemplates(), unexpected error: %v", err)
+	}
+
+	// A down notice fires the alert until a few resend intervals from now
+	start := time.Now()
+	if _, err := alert.Send(m.AlertNotice{MonitorName: "web", LastCheckOutput: "refused"}); err != nil {
+		t.Fatalf("Send(down), unexpected error: %v", err)
+	}
+
+	request := <-requests
+	if request.method != http.MethodPost {
+		t.Errorf("Send(down), expected POST, got %s", request.method)
+	}
+
+	alerts := decodeAlertmanagerAlerts(t, request)
+	if len(alerts) != 1 {
+		t.Fatalf("Send(down), expected 1 alert, got %q", request.body)
+	}
+
+	firing := alerts[0]
+	if firing.Labels["alertname"] != "MinitorMonitorDown" || firing.Labels["monitor"] != "web" || firing.Labels["severity"] != "page" {
+		t.Errorf("Send(down), unexpected labels %v", firing.Labels)
+	}
+
+	if firing.Annotations["summary"] != "web failed" || firing.Annotations["runbook_url"] != "https://wiki/web" ||
+		firing.Annotations["description"] == "" {
+		t.Errorf("Send(down), unexpected annotations %v", firing.Annotations)
+	}
+
+	if !firing.EndsAt.After(start.Add(3 * time.Minute)) {
+		t.Errorf("Send(down), expected endsAt several resend intervals in the future, got %v", firing.EndsAt)
+	}
+
+	// Nothing is resent before the resend interval has passed
+	if err := m.ResendFiringAlerts(&config, time.Now()); err != nil {
+		t.Fatalf("ResendFiringAlerts(), unexpected error: %v", err)
+	}
+
+	if len(requests) != 0 {
+		t.Errorf("ResendFiringAlerts(), expected no resend before the interval, got %q", (<-requests).body)
+	}
+
+	// Firing alerts are resent with the same start time once the interval passes
+	if err := m.ResendFiringAlerts(&config, time.Now().Add(2*time.Minute)); err != nil {
+		t.Fatalf("ResendFiringAlerts(), unexpected error: %v", err)
+	}
+
+	alerts = decodeAlertmanagerAlerts(t, <-requests)
+	if len(alerts) != 1 || alerts[0].Labels["monitor"] != "web" || !alerts[0].StartsAt.Equal(firing.StartsAt) ||
+		!alerts[0].EndsAt.After(firing.EndsAt) {
+		t.Errorf("ResendFiringAlerts(), unexpected resent alerts %v", alerts)
+	}
+
+	// An up notice resolves the alert and stops resends
+	if _, err := alert.Send(m.AlertNotice{MonitorName: "web", IsUp: true}); err != nil {
+		t.Fatalf("Send(up), unexpected error: %v", err)
+	}
+
+	alerts = decodeAlertmanagerAlerts(t, <-requests)
+	if len(alerts) != 1 || !alerts[0].StartsAt.Equal(firing.StartsAt) || alerts[0].EndsAt.After(time.Now()) {
+		t.Errorf("Send(up), expected resolved alert, got %v", alerts)
+	}
+
+	if err := m.ResendFiringAlerts(&config, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("ResendFiringAlerts(), unexpected error: %v", err)
+	}
+
+	if len(requests) != 0 {
+		t.Errorf("ResendFiringAlerts(), expected no resend after resolving, got %q", (<-requests).body)
+	}
+}
+
+func TestAlertmanagerAlertResolveSuppressedRecovery(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newWebhookServer(t, http.StatusOK)
+	alert := &m.Alert{Name: "alertmanager", Alertmanager: &m.AlertmanagerAlert{URL: server.URL}}
+	config := m.Config{Alerts: []*m.Alert{alert}, Monitors: []*m.Monitor{{Name: "web"}, {Name: "db", AlertCount: 1}}}
+
+	if err := alert.Init(); err != nil {
+		t.Fatalf("Init(), unexpected error: %v", err)
+	}
+
+	if err := alert.BuildTemplates(); err != nil {
+		t.Fatalf("BuildTemplates(), unexpected error: %v", err)
+	}
+
+	digest := m.NewDigestNotice([]m.AlertNotice{{MonitorName: "web"}, {MonitorName: "db"}})
+	if _, err := alert.Send(digest); err != nil {
+		t.Fatalf("Send(down), unexpected error: %v", err)
+	}
+
+	<-requests
+
+	// The recovery of web never reached the alert, so it is resolved without waiting for the interval
+	now := time.Now()
+	if err := m.ResendFiringAlerts(&config, now); err != nil {
+		t.Fatalf("ResendFiringAlerts(), unexpected error: %v", err)
+	}
+
+	alerts := decodeAlertmanagerAlerts(t, <-requests)
+	if len(alerts) != 1 || alerts[0].Labels["monitor"] != "web" || alerts[0].EndsAt.After(now) {
+		t.Errorf("ResendFiringAlerts(), expected resolved alert for web, got %v", alerts)
+	}
+
+	// Only db is still firing
+	if err := m.ResendFiringAlerts(&config, now.Add(time.Hour)); err != nil {
+		t.Fatalf("ResendFiringAlerts(), unexpected error: %v", err)
+	}
+
+	alerts = decodeAlertmanagerAlerts(t, <-requests)
+	if len(alerts) != 1 || alerts[0].Labels["monitor"] != "db" || !alerts[0].EndsAt.After(now.Add(time.Hour)) {
+		t.Errorf("ResendFiringAlerts(), expected only db to be resent, got %v", alerts)
+	}
+}
+
+func TestAlertmanagerAlertSendDigest(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newWebhookServer(t, http.StatusOK)
+	alert := m.Alert{Name: "alertmanager", Alertmanager: &m.AlertmanagerAlert{URL: server.URL}}
+
+	if err := alert.Init(); err != nil {
+		t.Fatalf("Init(), unexpected error: %v", err)
+	}
+
+	if err := alert.BuildTemplates(); err != nil {
+		t.Fatalf("BuildTemplates(), unexpected error: %v", err)
+	}
+
+	digest := m.NewDigestNotice([]m.AlertNotice{{MonitorName: "web"}, {MonitorName: "db", IsUp: true}})
+	if _, err := alert.Send(digest); err != nil {
+		t.Fatalf("Send(), unexpected error: %v", err)
+	}
+
+	alerts := decodeAlertmanagerAlerts(t, <-requests)
+	if len(alerts) != 2 || alerts[0].Labels["monitor"] != "web" || alerts[1].Labels["monitor"] != "db" {
+		t.Errorf("Send(), expected one alert per monitor in the digest, got %v", alerts)
+	}
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+var ErrAPIRequest = errors.New("API request failed")
+
+// apiClientTimeout limits how long the silence command waits for a response
+const apiClientTimeout = 10 * time.Second
+
+// MonitorStatus is a snapshot of the state of a monitor after its last check
+type MonitorStatus struct {
+	Name          string    `json:"name"`
+	IsUp          bool      `json:"is_up"`
+	IsFlapping    bool      `json:"is_flapping"`
+	InMaintenance bool      `json:"in_maintenance"`
+	IsUnreachable bool      `json:"is_unreachable"`
+	LastCheck     time.Time `json:"last_check"`
+}
+
+// StatusTracker holds the latest status of each monitor so it can be served while checks run
+type StatusTracker struct {
+	lock     sync.RWMutex
+	monitors map[string]MonitorStatus
+}
+
+// NewStatusTracker creates an empty StatusTracker
+func NewStatusTracker() *StatusTracker {
+	return &StatusTracker{monitors: map[string]MonitorStatus{}}
+}
+
+// SetMonitor records the current status of a monitor
+func (tracker *StatusTracker) SetMonitor(monitor *Monitor) {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+
+	tracker.monitors[monitor.Name] = MonitorStatus{
+		Name:          monitor.Name,
+		IsUp:          monitor.IsUp(),
+		IsFlapping:    monitor.IsFlapping(),
+		InMaintenance: monitor.InMaintenance(),
+		IsUnreachable: monitor.IsUnreachable(),
+		LastCheck:     monitor.lastCheck,
+	}
+}
+
+// Monitors returns the status of all monitors sorted by name
+func (tracker *StatusTracker) Monitors() []MonitorStatus {
+	tracker.lock.RLock()
+	defer tracker.lock.RUnlock()
+
+	monitors := make([]MonitorStatus, 0, len(tracker.monitors))
+	for _, status := range tracker.monitors {
+		monitors = append(monitors, status)
+	}
+
+	slices.SortFunc(monitors, func(a, b MonitorStatus) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return monitors
+}
+
+// SilenceRequest is the body used to create a silence through the API
+type SilenceRequest struct {
+	Matcher  string `json:"matcher"`
+	Duration string `json:"duration"`
+	Author   string `json:"author"`
+	Comment  string `json:"comment"`
+}
+
+// SilenceStatus is a Silence along with the time left until it expires
+type SilenceStatus struct {
+	Silence
+	Remaining string `json:"remaining"`
+}
+
+// StatusResponse is the body returned by the status endpoint
+type StatusResponse struct {
+	Monitors []MonitorStatus `json:"monitors"`
+	Silences []SilenceStatus `json:"silences"`
+}
+
+// NewAPIHandler creates an http handler for managing silences and reading status
+func NewAPIHandler(silences *SilenceStore, status *StatusTracker) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/silences", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, silences.Active(time.Now()))
+	})
+
+	mux.HandleFunc("POST /api/silences", func(w http.ResponseWriter, r *http.Request) {
+		var request SilenceRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("%w: %w", ErrInvalidSilence, err))
+
+			return
+		}
+
+		duration, err := time.ParseDuration(request.Duration)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("%w: %w", ErrInvalidSilence, err))
+
+			return
+		}
+
+		silence, err := silences.Add(NewSilence(request.Matcher, duration, request.Author, request.Comment))
+
+		switch {
+		case errors.Is(err, ErrInvalidSilence):
+			writeError(w, http.StatusBadRequest, err)
+		case err != nil:
+			writeError(w, http.StatusInternalServerError, err)
+		default:
+			writeJSON(w, http.StatusCreated, silence)
+		}
+	})
+
+	mux.HandleFunc("DELETE /api/silences/{id}", func(w http.ResponseWriter, r *http.Request) {
+		err := silences.Expire(r.PathValue("id"))
+
+		switch {
+		case errors.Is(err, ErrUnknownSilence):
+			writeError(w, http.StatusNotFound, err)
+		case err != nil:
+			writeError(w, http.StatusInternalServerError, err)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now()
+		response := StatusResponse{Monitors: status.Monitors(), Silences: []SilenceStatus{}}
+
+		for _, silence := range silences.Active(now) {
+			response.Silences = append(response.Silences, SilenceStatus{
+				Silence:   silence,
+				Remaining: silence.ExpiresAt.Sub(now).Round(time.Second).String(),
+			})
+		}
+
+		writeJSON(w, http.StatusOK, response)
+	})
+
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// ServeAPI starts an http server with the silence and status API
+func ServeAPI() {
+	host := net.JoinHostPort(APIAddress, strconv.Itoa(APIPort))
+
+	_ = http.ListenAndServe(host, NewAPIHandler(Silences, Status))
+}
+
+// RunSilenceCommand creates, lists or expires silences using the API of a running minitor
+func RunSilenceCommand(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("silence", flag.ContinueOnError)
+	apiURL := flags.String("api-url", "http://localhost:8081", "URL of the minitor API")
+	matcher := flags.String("matcher", "", "Name or glob pattern of monitors to silence")
+	duration := flags.String("duration", "1h", "How long the silence lasts")
+	author := flags.String("author", os.Getenv("USER"), "Who created the silence")
+	comment := flags.String("comment", "", "Reason for the silence")
+	list := flags.Bool("list", false, "List active silences instead of creating one")
+	expire := flags.String("expire", "", "ID of a silence to expire instead of creating one")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	baseURL := strings.TrimRight(*apiURL, "/")
+	client := &http.Client{Timeout: apiClientTimeout}
+
+	switch {
+	case *list:
+		var status StatusResponse
+		if err := doAPIRequest(client, http.MethodGet, baseURL+"/status", nil, &status); err != nil {
+			return err
+		}
+
+		writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "ID\tMATCHER\tREMAINING\tAUTHOR\tCOMMENT")
+
+		for _, silence := range status.Silences {
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", silence.ID, silence.Matcher, silence.Remaining, silence.Author, silence.Comment)
+		}
+
+		return writer.Flush()
+	case *expire != "":
+		if err := doAPIRequest(client, http.MethodDelete, baseURL+"/api/silences/"+*expire, nil, nil); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "Expired silence %s\n", *expire)
+
+		return nil
+	default:
+		request := SilenceRequest{Matcher: *matcher, Duration: *duration, Author: *author, Comment: *comment}
+
+		var silence Silence
+		if err := doAPIRequest(client, http.MethodPost, baseURL+"/api/silences", request, &silence); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "Created silence %s for %s until %s\n", silence.ID, silence.Matcher, silence.ExpiresAt.Format(time.RFC3339))
+
+		return nil
+	}
+}
+
+// doAPIRequest sends an optional JSON body and decodes the JSON response into result, if not nil
+func doAPIRequest(client *http.Client, method, url string, body any, result any) error {
+	var reqBody io.Reader
+
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+
+		reqBody = bytes.NewReader(content)
+	}
+
+	req, err := http.NewRequest(method, url, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrAPIRequest, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+
+		_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+
+		return fmt.Errorf("%w: %s: %s", ErrAPIRequest, resp.Status, apiErr.Error)
+	}
+
+	if result != nil {
+		if err = json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package main_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	m "git.iamthefij.com/iamthefij/minitor-go/v2"
+)
+
+func TestSilenceCommand(t *testing.T) {
+	t.Parallel()
+
+	silences := m.NewSilenceStore()
+	status := m.NewStatusTracker()
+	status.SetMonitor(&m.Monitor{Name: "web-1"})
+
+	server := httptest.NewServer(m.NewAPIHandler(silences, status))
+	defer server.Close()
+
+	var out bytes.Buffer
+
+	err := m.RunSilenceCommand(
+		[]string{"-api-url", server.URL, "-matcher", "web-*", "-duration", "2h", "-author", "me", "-comment", "upgrade"},
+		&out,
+	)
+	if err != nil {
+		t.Fatalf("RunSilenceCommand(create), unexpected error: %v", err)
+	}
+
+	active := silences.Active(time.Now())
+	if len(active) != 1 || active[0].Matcher != "web-*" || active[0].Author != "me" {
+		t.Fatalf("RunSilenceCommand(create), expected one silence for web-*, got %v", active)
+	}
+
+	if !strings.Contains(out.String(), active[0].ID) {
+		t.Errorf("RunSilenceCommand(create), expected output to contain id, got %q", out.String())
+	}
+
+	out.Reset()
+
+	if err = m.RunSilenceCommand([]string{"-api-url", server.URL, "-list"}, &out); err != nil {
+		t.Fatalf("RunSilenceCommand(list), unexpected error: %v", err)
+	}
+
+	if !strings.Contains(out.String(), active[0].ID) || !strings.Contains(out.String(), "upgrade") {
+		t.Errorf("RunSilenceCommand(list), expected silence in output, got %q", out.String())
+	}
+
+	err = m.RunSilenceCommand([]string{"-api-url", server.URL, "-matcher", "web", "-duration", "soon"}, &out)
+	if err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("RunSilenceCommand(invalid), expected a 400 error, got %v", err)
+	}
+
+	if err = m.RunSilenceCommand([]string{"-api-url", server.URL, "-expire", active[0].ID}, &out); err != nil {
+		t.Fatalf("RunSilenceCommand(expire), unexpected error: %v", err)
+	}
+
+	if actual := silences.Active(time.Now()); len(actual) != 0 {
+		t.Errorf("RunSilenceCommand(expire), expected no silences, got %v", actual)
+	}
+
+	err = m.RunSilenceCommand([]string{"-api-url", server.URL, "-expire", active[0].ID}, &out)
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("RunSilenceCommand(expire unknown), expected a 404 error, got %v", err)
+	}
+}
+
+func TestAPIStatus(t *testing.T) {
+	t.Parallel()
+
+	silences := m.NewSilenceStore()
+	status := m.NewStatusTracker()
+	status.SetMonitor(&m.Monitor{Name: "web-1"})
+
+	if _, err := silences.Add(m.NewSilence("web-*", time.Hour, "me", "upgrade")); err != nil {
+		t.Fatalf("Add(), unexpected error: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	m.NewAPIHandler(silences, status).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/status", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET /status, expected status 200, got %d", recorder.Code)
+	}
+
+	body := recorder.Body.String()
+	for _, expected := range []string{`"name":"web-1"`, `"matcher":"web-*"`, `"remaining":"1h0m0s"`} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("GET /status, expected body to contain %s, got %s", expected, body)
+		}
+	}
+}
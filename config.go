@@ -3,6 +3,8 @@ package main
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"git.iamthefij.com/iamthefij/slog"
@@ -18,6 +20,8 @@ var (
 	ErrNoMonitors     = errors.New("No monitors provided")
 	ErrInvalidMonitor = errors.New("Invalid monitor configuration")
 	ErrUnknownAlert   = errors.New("Unknown alert")
+	ErrUnknownMonitor = errors.New("Unknown monitor")
+	ErrDependencyLoop = errors.New("Monitor dependency cycle")
 )
 
 // Config type is contains all provided user configuration
@@ -25,12 +29,19 @@ type Config struct {
 	CheckIntervalStr string `hcl:"check_interval"`
 	CheckInterval    time.Duration
 
-	DefaultAlertAfter int        `hcl:"default_alert_after,optional"`
-	DefaultAlertEvery *int       `hcl:"default_alert_every,optional"`
-	DefaultAlertDown  []string   `hcl:"default_alert_down,optional"`
-	DefaultAlertUp    []string   `hcl:"default_alert_up,optional"`
-	Monitors          []*Monitor `hcl:"monitor,block"`
-	Alerts            []*Alert   `hcl:"alert,block"`
+	DefaultAlertAfter   int        `hcl:"default_alert_after,optional"`
+	DefaultAlertEvery   *int       `hcl:"default_alert_every,optional"`
+	DefaultAlertDown    []string   `hcl:"default_alert_down,optional"`
+	DefaultAlertUp      []string   `hcl:"default_alert_up,optional"`
+	DefaultRecoverAfter int        `hcl:"default_recover_after,optional"`
+	Monitors            []*Monitor `hcl:"monitor,block"`
+	Alerts              []*Alert   `hcl:"alert,block"`
+
+	Maintenance        []*Maintenance      `hcl:"maintenance,block"`
+	EscalationPolicies []*EscalationPolicy `hcl:"escalation_policy,block"`
+
+	RateLimit      *RateLimit `hcl:"rate_limit,block"`
+	RateLimitAlert string     `hcl:"rate_limit_alert,optional"`
 
 	alertLookup map[string]*Alert
 }
@@ -52,17 +63,56 @@ func (config *Config) Init() (err error) {
 		config.DefaultAlertEvery = &defaultDefaultAlertEvery
 	}
 
+	if config.DefaultRecoverAfter == 0 {
+		minRecoverAfter := 1
+		config.DefaultRecoverAfter = minRecoverAfter
+	}
+
 	for _, monitor := range config.Monitors {
 		if err = monitor.Init(
 			config.DefaultAlertAfter,
 			config.DefaultAlertEvery,
 			config.DefaultAlertDown,
 			config.DefaultAlertUp,
+			config.DefaultRecoverAfter,
 		); err != nil {
 			return
 		}
 	}
 
+	config.sortMonitorsByDependency()
+
+	for _, policy := range config.EscalationPolicies {
+		escalation := Escalation{Steps: policy.Steps}
+		if err = escalation.Init(); err != nil {
+			return fmt.Errorf("failed to initialize escalation policy %s: %w", policy.Name, err)
+		}
+	}
+
+	for _, monitor := range config.Monitors {
+		if policy, ok := config.GetEscalationPolicy(monitor.EscalationPolicy); ok && monitor.Escalation == nil {
+			monitor.escalation = &Escalation{Steps: policy.Steps}
+		}
+	}
+
+	for _, maintenance := range config.Maintenance {
+		if err = maintenance.Init(); err != nil {
+			return
+		}
+	}
+
+	for _, alert := range config.Alerts {
+		if err = alert.Init(); err != nil {
+			return
+		}
+	}
+
+	if config.RateLimit != nil {
+		if err = config.RateLimit.Init(); err != nil {
+			return fmt.Errorf("failed to initialize global rate limit: %w", err)
+		}
+	}
+
 	err = config.BuildAllTemplates()
 
 	return
@@ -81,6 +131,16 @@ func (config Config) IsValid() error {
 		err = errors.Join(err, alert.Validate())
 	}
 
+	if config.RateLimit != nil {
+		if limitErr := config.RateLimit.Validate(); limitErr != nil {
+			err = errors.Join(err, fmt.Errorf("global rate limit: %w", limitErr))
+		}
+	}
+
+	if _, ok := config.GetAlert(config.RateLimitAlert); config.RateLimitAlert != "" && !ok {
+		err = errors.Join(err, fmt.Errorf("%w: rate_limit_alert. %w: %s", ErrInvalidConfig, ErrUnknownAlert, config.RateLimitAlert))
+	}
+
 	// Validate monitors
 	if len(config.Monitors) == 0 {
 		err = errors.Join(err, ErrNoMonitors)
@@ -90,21 +150,202 @@ func (config Config) IsValid() error {
 		err = errors.Join(err, monitor.Validate())
 
 		// Check that all Monitor alerts actually exist
-		for _, isUp := range []bool{true, false} {
-			for _, alertName := range monitor.GetAlertNames(isUp) {
-				if _, ok := config.GetAlert(alertName); !ok {
-					err = errors.Join(
-						err,
-						fmt.Errorf("%w: %s. %w: %s", ErrInvalidMonitor, monitor.Name, ErrUnknownAlert, alertName),
-					)
-				}
+		alertNames := slices.Concat(monitor.GetAlertNames(true), monitor.GetAlertNames(false), monitor.AlertFlapping)
+		if monitor.Escalation != nil {
+			alertNames = slices.Concat(alertNames, monitor.Escalation.AllAlertNames())
+		}
+
+		for _, alertName := range alertNames {
+			if _, ok := config.GetAlert(alertName); !ok {
+				err = errors.Join(
+					err,
+					fmt.Errorf("%w: %s. %w: %s", ErrInvalidMonitor, monitor.Name, ErrUnknownAlert, alertName),
+				)
+			}
+		}
+
+		// Check that the escalation policy actually exists
+		if _, ok := config.GetEscalationPolicy(monitor.EscalationPolicy); monitor.EscalationPolicy != "" && !ok {
+			err = errors.Join(
+				err,
+				fmt.Errorf("%w: %s. %w: %s", ErrInvalidMonitor, monitor.Name, ErrUnknownEscalation, monitor.EscalationPolicy),
+			)
+		}
+
+		// Check that all Monitor dependencies actually exist
+		for _, parentName := range monitor.DependsOn {
+			if _, ok := config.GetMonitor(parentName); !ok {
+				err = errors.Join(
+					err,
+					fmt.Errorf("%w: %s. %w: %s", ErrInvalidMonitor, monitor.Name, ErrUnknownMonitor, parentName),
+				)
+			}
+		}
+	}
+
+	err = errors.Join(err, config.validateDependencyCycles())
+
+	// Validate escalation policies
+	for _, policy := range config.EscalationPolicies {
+		escalation := Escalation{Steps: policy.Steps}
+		if policyErr := escalation.Validate(); policyErr != nil {
+			err = errors.Join(err, fmt.Errorf("escalation policy %s: %w", policy.Name, policyErr))
+		}
+
+		for _, alertName := range escalation.AllAlertNames() {
+			if _, ok := config.GetAlert(alertName); !ok {
+				err = errors.Join(
+					err,
+					fmt.Errorf("%w: escalation policy %s. %w: %s", ErrInvalidEscalation, policy.Name, ErrUnknownAlert, alertName),
+				)
 			}
 		}
 	}
 
+	// Validate maintenance windows
+	for _, maintenance := range config.Maintenance {
+		err = errors.Join(err, maintenance.Validate())
+	}
+
 	return err
 }
 
+// validateDependencyCycles returns an error for each cycle found in monitor dependencies
+func (config Config) validateDependencyCycles() error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	var err error
+
+	state := map[string]int{}
+	path := []string{}
+
+	var visit func(monitor *Monitor)
+	visit = func(monitor *Monitor) {
+		state[monitor.Name] = visiting
+		path = append(path, monitor.Name)
+
+		for _, parentName := range monitor.DependsOn {
+			parent, ok := config.GetMonitor(parentName)
+			if !ok {
+				continue
+			}
+
+			switch state[parent.Name] {
+			case visiting:
+				cycle := slices.Concat(path[slices.Index(path, parent.Name):], []string{parent.Name})
+				err = errors.Join(err, fmt.Errorf(
+					"%w: %s. %w: %s", ErrInvalidMonitor, monitor.Name, ErrDependencyLoop, strings.Join(cycle, " -> "),
+				))
+			case unvisited:
+				visit(parent)
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[monitor.Name] = visited
+	}
+
+	for _, monitor := range config.Monitors {
+		if state[monitor.Name] == unvisited {
+			visit(monitor)
+		}
+	}
+
+	return err
+}
+
+// sortMonitorsByDependency orders monitors so that parents are checked before their dependents.
+// Monitors in a dependency cycle keep their configured order. Cycles are reported by IsValid.
+func (config *Config) sortMonitorsByDependency() {
+	sorted := make([]*Monitor, 0, len(config.Monitors))
+	added := map[string]bool{}
+
+	for len(sorted) < len(config.Monitors) {
+		progress := false
+
+		for _, monitor := range config.Monitors {
+			if added[monitor.Name] {
+				continue
+			}
+
+			ready := true
+
+			for _, parentName := range monitor.DependsOn {
+				if _, ok := config.GetMonitor(parentName); ok && !added[parentName] {
+					ready = false
+				}
+			}
+
+			if ready {
+				sorted = append(sorted, monitor)
+				added[monitor.Name] = true
+				progress = true
+			}
+		}
+
+		if !progress {
+			// Only cycles remain, so add them as they were configured
+			for _, monitor := range config.Monitors {
+				if !added[monitor.Name] {
+					sorted = append(sorted, monitor)
+					added[monitor.Name] = true
+				}
+			}
+		}
+	}
+
+	config.Monitors = sorted
+}
+
+// GetMonitor returns a monitor by name
+func (c Config) GetMonitor(name string) (*Monitor, bool) {
+	for _, monitor := range c.Monitors {
+		if monitor.Name == name {
+			return monitor, true
+		}
+	}
+
+	return nil, false
+}
+
+// GetEscalationPolicy returns an escalation policy by name
+func (c Config) GetEscalationPolicy(name string) (*EscalationPolicy, bool) {
+	for _, policy := range c.EscalationPolicies {
+		if policy.Name == name {
+			return policy, true
+		}
+	}
+
+	return nil, false
+}
+
+// InMaintenance returns true if any maintenance window matching the monitor is active at the given time
+func (c Config) InMaintenance(monitor *Monitor, now time.Time) bool {
+	for _, maintenance := range c.Maintenance {
+		if maintenance.Matches(monitor.Name) && maintenance.IsActive(now) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsUnreachable returns true if any monitor that the given monitor depends on is failing, down or
+// unreachable. A failing parent counts even before it reaches its own alert_after
+func (c Config) IsUnreachable(monitor *Monitor) bool {
+	for _, parentName := range monitor.DependsOn {
+		if parent, ok := c.GetMonitor(parentName); ok && (parent.IsFailing() || !parent.IsUp() || parent.IsUnreachable()) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // GetAlert returns an alert by name
 func (c Config) GetAlert(name string) (*Alert, bool) {
 	if c.alertLookup == nil {
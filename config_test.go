@@ -20,7 +20,14 @@ func TestLoadConfig(t *testing.T) {
 		{"./test/invalid-config-missing-alerts.hcl", m.ErrInvalidConfig, "Invalid config general"},
 		{"./test/invalid-config-invalid-duration.hcl", m.ErrConfigInit, "Invalid config type for key"},
 		{"./test/invalid-config-unknown-alert.hcl", m.ErrUnknownAlert, "Invalid config unknown alert"},
+		{"./test/invalid-config-unknown-dependency.hcl", m.ErrUnknownMonitor, "Invalid config unknown dependency"},
+		{"./test/invalid-config-dependency-cycle.hcl", m.ErrDependencyLoop, "Invalid config dependency cycle"},
+		{"./test/invalid-config-unknown-escalation.hcl", m.ErrUnknownEscalation, "Invalid config unknown escalation policy"},
+		{"./test/invalid-config-escalation-step.hcl", m.ErrInvalidEscalation, "Invalid config escalation step"},
+		{"./test/invalid-config-escalation-no-first-alert.hcl", m.ErrInvalidMonitor, "Invalid config escalation without first alert"},
 		{"./test/valid-config-default-values.hcl", nil, "Valid config file with default values"},
+		{"./test/valid-config-dependencies.hcl", nil, "Valid config file with dependencies"},
+		{"./test/valid-config-escalation.hcl", nil, "Valid config file with escalation"},
 		{"./test/valid-config.hcl", nil, "Valid config file"},
 	}
 	for _, c := range cases {
@@ -49,20 +56,22 @@ func TestDefaultConfig(t *testing.T) {
 		{
 			"./test/valid-config-default-values.hcl",
 			m.Config{
-				CheckInterval:     1 * time.Second,
-				DefaultAlertAfter: 2,
-				DefaultAlertEvery: Ptr(0),
-				DefaultAlertDown:  []string{"log_command"},
+				CheckInterval:       1 * time.Second,
+				DefaultAlertAfter:   2,
+				DefaultAlertEvery:   Ptr(0),
+				DefaultAlertDown:    []string{"log_command"},
+				DefaultRecoverAfter: 3,
 			},
 			"override defaults",
 		},
 		{
 			"./test/valid-config.hcl",
 			m.Config{
-				CheckInterval:     30 * time.Second,
-				DefaultAlertAfter: 1,
-				DefaultAlertEvery: Ptr(-1),
-				DefaultAlertDown:  []string{},
+				CheckInterval:       30 * time.Second,
+				DefaultAlertAfter:   1,
+				DefaultAlertEvery:   Ptr(-1),
+				DefaultAlertDown:    []string{},
+				DefaultRecoverAfter: 1,
 			},
 			"default defaults",
 		},
@@ -88,6 +97,10 @@ func TestDefaultConfig(t *testing.T) {
 				t.Errorf("Got unexpected DefaultAlertEvery from file %q: expected=%v actual=%v", c.configPath, *c.expectedResult.DefaultAlertEvery, *config.DefaultAlertEvery)
 			}
 
+			if config.DefaultRecoverAfter != c.expectedResult.DefaultRecoverAfter {
+				t.Errorf("Got unexpected DefaultRecoverAfter from file %q: expected=%v actual=%v", c.configPath, c.expectedResult.DefaultRecoverAfter, config.DefaultRecoverAfter)
+			}
+
 			if !m.EqualSliceString(config.DefaultAlertUp, c.expectedResult.DefaultAlertUp) {
 				t.Errorf("Got unexpected DefaultAlertUp from file %q: expected=%v actual=%v", c.configPath, c.expectedResult.DefaultAlertUp, config.DefaultAlertUp)
 			}
@@ -116,6 +129,10 @@ func TestDefaultConfig(t *testing.T) {
 				t.Errorf("Got unexpected AlertEvery from file %q: expected=%v actual=%v", c.configPath, *c.expectedResult.DefaultAlertEvery, *defaultMonitor.AlertEvery)
 			}
 
+			if defaultMonitor.RecoverAfter != c.expectedResult.DefaultRecoverAfter {
+				t.Errorf("Got unexpected RecoverAfter from file %q: expected=%v actual=%v", c.configPath, c.expectedResult.DefaultRecoverAfter, defaultMonitor.RecoverAfter)
+			}
+
 			if !m.EqualSliceString(defaultMonitor.AlertUp, c.expectedResult.DefaultAlertUp) {
 				t.Errorf("Got unexpected AlertUp from file %q: expected=%v actual=%v", c.configPath, c.expectedResult.DefaultAlertUp, defaultMonitor.AlertUp)
 			}
@@ -125,6 +142,27 @@ func TestDefaultConfig(t *testing.T) {
 	}
 }
 
+// TestDependencyOrder tests that monitors are checked after the monitors they depend on
+func TestDependencyOrder(t *testing.T) {
+	t.Parallel()
+
+	config, err := m.LoadConfig("./test/valid-config-dependencies.hcl")
+	if err != nil {
+		t.Fatalf("TestDependencyOrder(load), expected=no_error actual=%v", err)
+	}
+
+	expected := []string{"Router", "Switch", "Server"}
+	actual := []string{}
+
+	for _, monitor := range config.Monitors {
+		actual = append(actual, monitor.Name)
+	}
+
+	if !m.EqualSliceString(actual, expected) {
+		t.Errorf("TestDependencyOrder, expected=%v actual=%v", expected, actual)
+	}
+}
+
 // TestMultiLineConfig is a more complicated test stepping through the parsing
 // and execution of mutli-line strings presented in YAML
 func TestMultiLineConfig(t *testing.T) {
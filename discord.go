@@ -0,0 +1,216 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"git.iamthefij.com/iamthefij/slog"
+)
+
+// ErrInvalidDiscord indicates that a discord alert is not properly configured
+var ErrInvalidDiscord = errors.New("Invalid discord configuration")
+
+const (
+	// discordMaxAttempts is how many times a message is sent when Discord responds with rate limits
+	discordMaxAttempts = 3
+	// discordMaxFieldLength is the longest value Discord accepts for an embed field
+	discordMaxFieldLength = 1024
+)
+
+// DiscordAlert is a built in alert type that posts an embed to a Discord webhook
+type DiscordAlert struct {
+	URL         string `hcl:"url"`
+	Username    string `hcl:"username,optional"`
+	AvatarURL   string `hcl:"avatar_url,optional"`
+	Content     string `hcl:"content,optional"`
+	Title       string `hcl:"title,optional"`
+	Description string `hcl:"description,optional"`
+
+	contentTemplate     *template.Template
+	titleTemplate       *template.Template
+	descriptionTemplate *template.Template
+}
+
+type discordMessage struct {
+	Content   string         `json:"content,omitempty"`
+	Username  string         `json:"username,omitempty"`
+	AvatarURL string         `json:"avatar_url,omitempty"`
+	Embeds    []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title       string              `json:"title"`
+	Description string              `json:"description,omitempty"`
+	Color       int64               `json:"color"`
+	Timestamp   string              `json:"timestamp"`
+	Fields      []discordEmbedField `json:"fields"`
+}
+
+type discordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+// discordRateLimit is the body of a 429 response from Discord
+type discordRateLimit struct {
+	RetryAfter float64 `json:"retry_after"`
+}
+
+// Validate checks that the DiscordAlert is properly configured and returns errors if not
+func (discord DiscordAlert) Validate() error {
+	var err error
+
+	err = errors.Join(err, validateHTTPURL(ErrInvalidDiscord, "url", discord.URL))
+
+	if discord.AvatarURL != "" {
+		err = errors.Join(err, validateHTTPURL(ErrInvalidDiscord, "avatar_url", discord.AvatarURL))
+	}
+
+	return err
+}
+
+// BuildTemplates compiles the content, title and description templates for the DiscordAlert
+func (discord *DiscordAlert) BuildTemplates(name string, funcs template.FuncMap) error {
+	var err error
+
+	discord.contentTemplate, err = template.New(name + "-content").Funcs(funcs).Parse(discord.Content)
+	if err != nil {
+		return err
+	}
+
+	title := discord.Title
+	if title == "" {
+		title = defaultNoticeTitle
+	}
+
+	discord.titleTemplate, err = template.New(name + "-title").Funcs(funcs).Parse(title)
+	if err != nil {
+		return err
+	}
+
+	discord.descriptionTemplate, err = template.New(name + "-description").Funcs(funcs).Parse(discord.Description)
+
+	return err
+}
+
+// Send renders the embed and posts it to the webhook url, waiting and retrying when rate limited
+func (discord DiscordAlert) Send(notice AlertNotice) (string, error) {
+	if discord.contentTemplate == nil || discord.titleTemplate == nil || discord.descriptionTemplate == nil {
+		return "", fmt.Errorf("No templates compiled for discord: %w", errNoTemplate)
+	}
+
+	message, err := discord.buildMessage(notice)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to encode payload: %w", ErrAlertFailed, err)
+	}
+
+	headers := map[string]string{"Content-Type": "application/json"}
+
+	for attempt := 1; ; attempt++ {
+		resp, respBody, err := doHTTPRequest(http.MethodPost, discord.URL, headers, body)
+		if err != nil {
+			return respBody, err
+		}
+
+		if resp.StatusCode != http.StatusTooManyRequests || attempt >= discordMaxAttempts {
+			return respBody, checkHTTPStatus(resp)
+		}
+
+		retryAfter, err := discordRetryAfter(resp, respBody)
+		if err != nil {
+			return respBody, err
+		}
+
+		slog.Warningf("Discord rate limited alert for %s. Retrying in %s", notice.MonitorName, retryAfter)
+		time.Sleep(retryAfter)
+	}
+}
+
+// buildMessage renders the templates into a message with a single embed
+func (discord DiscordAlert) buildMessage(notice AlertNotice) (discordMessage, error) {
+	var content, title, description strings.Builder
+
+	if err := discord.contentTemplate.Execute(&content, notice); err != nil {
+		return discordMessage{}, err
+	}
+
+	if err := discord.titleTemplate.Execute(&title, notice); err != nil {
+		return discordMessage{}, err
+	}
+
+	if err := discord.descriptionTemplate.Execute(&description, notice); err != nil {
+		return discordMessage{}, err
+	}
+
+	color, err := strconv.ParseInt(strings.TrimPrefix(noticeColor(notice), "#"), 16, 32)
+	if err != nil {
+		return discordMessage{}, fmt.Errorf("failed to parse embed color: %w", err)
+	}
+
+	fields := []discordEmbedField{
+		{Name: "Failure count", Value: fmt.Sprint(notice.FailureCount), Inline: true},
+		{Name: "Alert count", Value: fmt.Sprint(notice.AlertCount), Inline: true},
+		{Name: "Last success", Value: formatLastSuccess(notice.LastSuccess), Inline: true},
+	}
+
+	if len(notice.FailedTargets) > 0 {
+		fields = append(fields, discordEmbedField{
+			Name:  "Failed targets",
+			Value: truncateText(strings.Join(notice.FailedTargets, ", "), discordMaxFieldLength),
+		})
+	}
+
+	if output := strings.TrimSpace(notice.LastCheckOutput); output != "" {
+		fence := "```"
+		fields = append(fields, discordEmbedField{
+			Name:  "Output",
+			Value: fence + truncateText(output, discordMaxFieldLength-2*len(fence)) + fence,
+		})
+	}
+
+	return discordMessage{
+		Content:   content.String(),
+		Username:  discord.Username,
+		AvatarURL: discord.AvatarURL,
+		Embeds: []discordEmbed{{
+			Title:       title.String(),
+			Description: description.String(),
+			Color:       color,
+			Timestamp:   time.Now().UTC().Format(time.RFC3339),
+			Fields:      fields,
+		}},
+	}, nil
+}
+
+// discordRetryAfter returns how long to wait before retrying a rate limited request
+func discordRetryAfter(resp *http.Response, respBody string) (time.Duration, error) {
+	var rateLimit discordRateLimit
+	if err := json.Unmarshal([]byte(respBody), &rateLimit); err != nil || rateLimit.RetryAfter <= 0 {
+		// Fall back to the standard header, which is in whole seconds
+		seconds, headerErr := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64)
+		if headerErr != nil {
+			return 0, fmt.Errorf("%w: rate limited without a valid retry_after", ErrAlertFailed)
+		}
+
+		rateLimit.RetryAfter = seconds
+	}
+
+	retryAfter := time.Duration(rateLimit.RetryAfter * float64(time.Second))
+	if retryAfter > alertHTTPTimeout {
+		return 0, fmt.Errorf("%w: rate limited for %s, which is longer than the alert timeout", ErrAlertFailed, retryAfter)
+	}
+
+	return retryAfter, nil
+}
@@ -0,0 +1,204 @@
+package main_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	m "git.iamthefij.com/iamthefij/minitor-go/v2"
+)
+
+// newRateLimitedServer starts a server that responds to the first limited requests with a Discord
+// rate limit and records the bodies of all requests
+func newRateLimitedServer(t *testing.T, limited int32, retryAfter string) (*httptest.Server, *atomic.Int32, chan string) {
+	t.Helper()
+
+	count := &atomic.Int32{}
+	bodies := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- string(body)
+
+		if count.Add(1) <= limited {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"message": "You are being rate limited.", "retry_after": ` + retryAfter + `, "global": false}`))
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, count, bodies
+}
+
+func TestDiscordAlertValidate(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		discord  m.DiscordAlert
+		expected error
+		name     string
+	}{
+		{m.DiscordAlert{URL: "https://discord.com/api/webhooks/1/x"}, nil, "Valid"},
+		{m.DiscordAlert{URL: "https://discord.com/api/webhooks/1/x", AvatarURL: "https://example.com/a.png"}, nil, "Valid avatar"},
+		{m.DiscordAlert{}, m.ErrInvalidDiscord, "No url"},
+		{m.DiscordAlert{URL: "https://discord.com/api/webhooks/1/x", AvatarURL: "a.png"}, m.ErrInvalidDiscord, "Relative avatar"},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := c.discord.Validate()
+			hasErr := (actual != nil)
+			expectErr := (c.expected != nil)
+
+			if hasErr != expectErr || !errors.Is(actual, c.expected) {
+				t.Errorf("Validate(%v), expected=%v actual=%v", c.name, c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDiscordAlertSend(t *testing.T) {
+	t.Parallel()
+
+	type discordPayload struct {
+		Content   string `json:"content"`
+		Username  string `json:"username"`
+		AvatarURL string `json:"avatar_url"`
+		Embeds    []struct {
+			Title       string `json:"title"`
+			Description string `json:"description"`
+			Color       int    `json:"color"`
+			Fields      []struct {
+				Name  string `json:"name"`
+				Value string `json:"value"`
+			} `json:"fields"`
+		} `json:"embeds"`
+	}
+
+	cases := []struct {
+		discord       m.DiscordAlert
+		notice        m.AlertNotice
+		expectedTitle string
+		expectedColor int
+		expected      []string
+		name          string
+	}{
+		{
+			m.DiscordAlert{Username: "minitor", AvatarURL: "https://example.com/a.png", Content: "@here"},
+			m.AlertNotice{MonitorName: "web", FailureCount: 2, AlertCount: 1, FailedTargets: []string{"a", "b"}, LastCheckOutput: "boom"},
+			"web is down",
+			0xa30200,
+			[]string{"Failure count=2", "Alert count=1", "Last success=Never", "Failed targets=a, b", "Output=```boom```"},
+			"Down",
+		},
+		{
+			m.DiscordAlert{Title: "{{.MonitorName}} recovered", Description: "after {{.FailureCount}} failures"},
+			m.AlertNotice{MonitorName: "web", IsUp: true, FailureCount: 4},
+			"web recovered",
+			0x2eb886,
+			[]string{"Failure count=4"},
+			"Up",
+		},
+	}
+
+	for _, c := range cases {
+		server, _, bodies := newRateLimitedServer(t, 0, "0")
+		alert := m.Alert{Name: "discord", Discord: &c.discord}
+		alert.Discord.URL = server.URL
+
+		if err := alert.BuildTemplates(); err != nil {
+			t.Fatalf("BuildTemplates(%v), unexpected error: %v", c.name, err)
+		}
+
+		if _, err := alert.Send(c.notice); err != nil {
+			t.Fatalf("Send(%v), unexpected error: %v", c.name, err)
+		}
+
+		body := <-bodies
+
+		var payload discordPayload
+		if err := json.Unmarshal([]byte(body), &payload); err != nil {
+			t.Fatalf("Send(%v), invalid json body %q: %v", c.name, body, err)
+		}
+
+		if payload.Content != c.discord.Content || payload.Username != c.discord.Username ||
+			payload.AvatarURL != c.discord.AvatarURL || len(payload.Embeds) != 1 {
+			t.Errorf("Send(%v), unexpected payload %q", c.name, body)
+
+			continue
+		}
+
+		embed := payload.Embeds[0]
+		if embed.Title != c.expectedTitle || embed.Color != c.expectedColor {
+			t.Errorf("Send(%v), unexpected embed title=%q color=%x", c.name, embed.Title, embed.Color)
+		}
+
+		fields := []string{}
+		for _, field := range embed.Fields {
+			fields = append(fields, field.Name+"="+field.Value)
+		}
+
+		for _, expected := range c.expected {
+			if !strings.Contains(strings.Join(fields, "\n"), expected) {
+				t.Errorf("Send(%v), expected field %q in %q", c.name, expected, fields)
+			}
+		}
+	}
+}
+
+func TestDiscordAlertRateLimited(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		limited       int32
+		retryAfter    string
+		expected      error
+		expectedCount int32
+		name          string
+	}{
+		{1, "0.01", nil, 2, "Retried after rate limit"},
+		{5, "0.01", m.ErrAlertFailed, 3, "Gives up after max attempts"},
+		{1, "60", m.ErrAlertFailed, 1, "Retry after too long"},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			server, count, _ := newRateLimitedServer(t, c.limited, c.retryAfter)
+			alert := m.Alert{Name: "discord", Discord: &m.DiscordAlert{URL: server.URL}}
+
+			if err := alert.BuildTemplates(); err != nil {
+				t.Fatalf("BuildTemplates(%v), unexpected error: %v", c.name, err)
+			}
+
+			_, err := alert.Send(m.AlertNotice{MonitorName: "web"})
+			hasErr := (err != nil)
+			expectErr := (c.expected != nil)
+
+			if hasErr != expectErr || !errors.Is(err, c.expected) {
+				t.Errorf("Send(%v), expected=%v actual=%v", c.name, c.expected, err)
+			}
+
+			if count.Load() != c.expectedCount {
+				t.Errorf("Send(%v), expected %d requests, got %d", c.name, c.expectedCount, count.Load())
+			}
+		})
+	}
+}
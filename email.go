@@ -0,0 +1,341 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ErrInvalidEmail indicates that an email alert is not properly configured
+var ErrInvalidEmail = errors.New("Invalid email configuration")
+
+const (
+	emailSecurityStartTLS = "starttls"
+	emailSecurityTLS      = "tls"
+	emailSecurityNone     = "none"
+)
+
+// defaultEmailPorts are the standard ports for each email security mode
+var defaultEmailPorts = map[string]int{
+	emailSecurityStartTLS: 587,
+	emailSecurityTLS:      465,
+	emailSecurityNone:     25,
+}
+
+// EmailAlert is a built in alert type that sends an email over SMTP
+type EmailAlert struct {
+	Host          string   `hcl:"host"`
+	Port          int      `hcl:"port,optional"`
+	Security      string   `hcl:"security,optional"`
+	TLSSkipVerify bool     `hcl:"tls_skip_verify,optional"`
+	Username      string   `hcl:"username,optional"`
+	Password      string   `hcl:"password,optional"`
+	From          string   `hcl:"from"`
+	To            []string `hcl:"to"`
+	Subject       string   `hcl:"subject,optional"`
+	Body          string   `hcl:"body,optional"`
+	HTMLBody      string   `hcl:"html_body,optional"`
+
+	subjectTemplate  *template.Template
+	bodyTemplate     *template.Template
+	htmlBodyTemplate *htmltemplate.Template
+}
+
+// Validate checks that the EmailAlert is properly configured and returns errors if not
+func (email EmailAlert) Validate() error {
+	var err error
+
+	if email.Host == "" {
+		err = errors.Join(err, fmt.Errorf("%w: host is required", ErrInvalidEmail))
+	}
+
+	if _, ok := defaultEmailPorts[email.security()]; !ok {
+		err = errors.Join(err, fmt.Errorf(
+			"%w: security must be one of %s, %s or %s",
+			ErrInvalidEmail,
+			emailSecurityStartTLS,
+			emailSecurityTLS,
+			emailSecurityNone,
+		))
+	}
+
+	if _, addrErr := mail.ParseAddress(email.From); addrErr != nil {
+		err = errors.Join(err, fmt.Errorf("%w: invalid from address %q", ErrInvalidEmail, email.From))
+	}
+
+	if len(email.To) == 0 {
+		err = errors.Join(err, fmt.Errorf("%w: at least one to address is required", ErrInvalidEmail))
+	}
+
+	for _, to := range email.To {
+		if _, addrErr := mail.ParseAddress(to); addrErr != nil {
+			err = errors.Join(err, fmt.Errorf("%w: invalid to address %q", ErrInvalidEmail, to))
+		}
+	}
+
+	if email.Password != "" && email.Username == "" {
+		err = errors.Join(err, fmt.Errorf("%w: password is configured without a username", ErrInvalidEmail))
+	}
+
+	return err
+}
+
+// BuildTemplates compiles the subject and body templates for the EmailAlert. Values in the html
+// body are escaped automatically
+func (email *EmailAlert) BuildTemplates(name string, funcs template.FuncMap) error {
+	var err error
+
+	subject := email.Subject
+	if subject == "" {
+		subject = defaultNoticeTitle
+	}
+
+	email.subjectTemplate, err = template.New(name + "-subject").Funcs(funcs).Parse(subject)
+	if err != nil {
+		return err
+	}
+
+	body := email.Body
+	if body == "" {
+		body = defaultNoticeMessage
+	}
+
+	email.bodyTemplate, err = template.New(name + "-body").Funcs(funcs).Parse(body)
+	if err != nil {
+		return err
+	}
+
+	if email.HTMLBody != "" {
+		email.htmlBodyTemplate, err = htmltemplate.New(name + "-html_body").Funcs(funcs).Parse(email.HTMLBody)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Send renders the email and delivers it to the SMTP server
+func (email EmailAlert) Send(notice AlertNotice) (string, error) {
+	if email.subjectTemplate == nil || email.bodyTemplate == nil {
+		return "", fmt.Errorf("No templates compiled for email: %w", errNoTemplate)
+	}
+
+	message, err := email.buildMessage(notice)
+	if err != nil {
+		return "", err
+	}
+
+	if err = email.deliver(message); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Sent email to %s", strings.Join(email.To, ", ")), nil
+}
+
+func (email EmailAlert) security() string {
+	if email.Security == "" {
+		return emailSecurityStartTLS
+	}
+
+	return strings.ToLower(email.Security)
+}
+
+func (email EmailAlert) address() string {
+	port := email.Port
+	if port == 0 {
+		port = defaultEmailPorts[email.security()]
+	}
+
+	return net.JoinHostPort(email.Host, fmt.Sprint(port))
+}
+
+// buildMessage renders the templates into a MIME message, with an alternative HTML part if configured
+func (email EmailAlert) buildMessage(notice AlertNotice) ([]byte, error) {
+	var subject, body strings.Builder
+
+	if err := email.subjectTemplate.Execute(&subject, notice); err != nil {
+		return nil, err
+	}
+
+	if err := email.bodyTemplate.Execute(&body, notice); err != nil {
+		return nil, err
+	}
+
+	var message bytes.Buffer
+
+	fmt.Fprintf(&message, "From: %s\r\n", email.From)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(email.To, ", "))
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", strings.TrimSpace(subject.String())))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "MIME-Version: 1.0\r\n")
+
+	if email.htmlBodyTemplate == nil {
+		fmt.Fprintf(&message, "Content-Type: text/plain; charset=utf-8\r\n")
+		fmt.Fprintf(&message, "Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+		if err := writeQuotedPrintable(&message, body.String()); err != nil {
+			return nil, err
+		}
+
+		return message.Bytes(), nil
+	}
+
+	var htmlBody strings.Builder
+	if err := email.htmlBodyTemplate.Execute(&htmlBody, notice); err != nil {
+		return nil, err
+	}
+
+	parts := multipart.NewWriter(&message)
+
+	fmt.Fprintf(&message, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", parts.Boundary())
+
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", body.String()},
+		{"text/html; charset=utf-8", htmlBody.String()},
+	} {
+		writer, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to build email: %w", err)
+		}
+
+		if err = writeQuotedPrintable(writer, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := parts.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build email: %w", err)
+	}
+
+	return message.Bytes(), nil
+}
+
+// deliver connects to the SMTP server and sends the message to all recipients
+func (email EmailAlert) deliver(message []byte) error {
+	tlsConfig := &tls.Config{
+		ServerName:         email.Host,
+		InsecureSkipVerify: email.TLSSkipVerify, //nolint:gosec
+	}
+	dialer := &net.Dialer{Timeout: alertHTTPTimeout}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+
+	if email.security() == emailSecurityTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", email.address(), tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", email.address())
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: failed to connect to smtp server: %w", ErrAlertFailed, err)
+	}
+
+	_ = conn.SetDeadline(time.Now().Add(alertHTTPTimeout))
+
+	client, err := smtp.NewClient(conn, email.Host)
+	if err != nil {
+		conn.Close()
+
+		return fmt.Errorf("%w: failed to connect to smtp server: %w", ErrAlertFailed, err)
+	}
+	defer client.Close()
+
+	if email.security() == emailSecurityStartTLS {
+		if err = client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("%w: failed to start tls: %w", ErrAlertFailed, err)
+		}
+	}
+
+	if email.Username != "" {
+		auth := smtp.PlainAuth("", email.Username, email.Password, email.Host)
+		if err = client.Auth(auth); err != nil {
+			return fmt.Errorf("%w: failed to authenticate: %w", ErrAlertFailed, err)
+		}
+	}
+
+	if err = sendSMTPMessage(client, email.From, email.To, message); err != nil {
+		return fmt.Errorf("%w: %w", ErrAlertFailed, err)
+	}
+
+	return nil
+}
+
+// sendSMTPMessage sends a message over an open SMTP client
+func sendSMTPMessage(client *smtp.Client, from string, to []string, message []byte) error {
+	fromAddress, err := mail.ParseAddress(from)
+	if err != nil {
+		return fmt.Errorf("invalid from address: %w", err)
+	}
+
+	if err = client.Mail(fromAddress.Address); err != nil {
+		return fmt.Errorf("smtp server rejected sender: %w", err)
+	}
+
+	for _, recipient := range to {
+		toAddress, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return fmt.Errorf("invalid to address: %w", err)
+		}
+
+		if err = client.Rcpt(toAddress.Address); err != nil {
+			return fmt.Errorf("smtp server rejected recipient %s: %w", toAddress.Address, err)
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp server rejected data: %w", err)
+	}
+
+	if _, err = writer.Write(message); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+
+	if err = writer.Close(); err != nil {
+		return fmt.Errorf("smtp server rejected message: %w", err)
+	}
+
+	if err = client.Quit(); err != nil {
+		return fmt.Errorf("failed to close smtp session: %w", err)
+	}
+
+	return nil
+}
+
+// writeQuotedPrintable writes content to w using quoted-printable encoding
+func writeQuotedPrintable(w io.Writer, content string) error {
+	writer := quotedprintable.NewWriter(w)
+
+	if _, err := writer.Write([]byte(content)); err != nil {
+		return fmt.Errorf("failed to encode email body: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to encode email body: %w", err)
+	}
+
+	return nil
+}
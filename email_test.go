@@ -0,0 +1,232 @@
+package main_test
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	m "git.iamthefij.com/iamthefij/minitor-go/v2"
+)
+
+// smtpMessage captures a message received by a test SMTP server
+type smtpMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+// newSMTPServer starts a minimal SMTP server without TLS that records delivered messages
+func newSMTPServer(t *testing.T) (string, int, chan smtpMessage) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start smtp server: %v", err)
+	}
+
+	t.Cleanup(func() { listener.Close() })
+
+	messages := make(chan smtpMessage, 10)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go serveSMTP(conn, messages)
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+
+	return addr.IP.String(), addr.Port, messages
+}
+
+func serveSMTP(conn net.Conn, messages chan smtpMessage) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	message := smtpMessage{}
+
+	fmt.Fprint(conn, "220 localhost test\r\n")
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		command := strings.ToUpper(strings.TrimSpace(line))
+
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			fmt.Fprint(conn, "250-localhost\r\n250 8BITMIME\r\n")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			message.from = smtpPath(line)
+			fmt.Fprint(conn, "250 OK\r\n")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			message.to = append(message.to, smtpPath(line))
+			fmt.Fprint(conn, "250 OK\r\n")
+		case command == "DATA":
+			fmt.Fprint(conn, "354 Go ahead\r\n")
+
+			var data strings.Builder
+
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+
+				if dataLine == ".\r\n" {
+					break
+				}
+
+				data.WriteString(dataLine)
+			}
+
+			message.data = data.String()
+			messages <- message
+			message = smtpMessage{}
+
+			fmt.Fprint(conn, "250 OK\r\n")
+		case command == "QUIT":
+			fmt.Fprint(conn, "221 Bye\r\n")
+
+			return
+		default:
+			fmt.Fprint(conn, "502 Not implemented\r\n")
+		}
+	}
+}
+
+// smtpPath returns the address between angle brackets of a MAIL or RCPT command
+func smtpPath(line string) string {
+	start := strings.Index(line, "<")
+	end := strings.Index(line, ">")
+
+	if start < 0 || end < start {
+		return ""
+	}
+
+	return line[start+1 : end]
+}
+
+func TestEmailAlertValidate(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		email    m.EmailAlert
+		expected error
+		name     string
+	}{
+		{m.EmailAlert{Host: "smtp.example.com", From: "minitor@example.com", To: []string{"Ops <ops@example.com>"}}, nil, "Valid"},
+		{m.EmailAlert{Host: "smtp.example.com", Security: "TLS", From: "minitor@example.com", To: []string{"ops@example.com"}}, nil, "Valid tls"},
+		{m.EmailAlert{From: "minitor@example.com", To: []string{"ops@example.com"}}, m.ErrInvalidEmail, "No host"},
+		{m.EmailAlert{Host: "smtp.example.com", Security: "ssl", From: "minitor@example.com", To: []string{"ops@example.com"}}, m.ErrInvalidEmail, "Invalid security"},
+		{m.EmailAlert{Host: "smtp.example.com", From: "minitor", To: []string{"ops@example.com"}}, m.ErrInvalidEmail, "Invalid from"},
+		{m.EmailAlert{Host: "smtp.example.com", From: "minitor@example.com"}, m.ErrInvalidEmail, "No to"},
+		{m.EmailAlert{Host: "smtp.example.com", From: "minitor@example.com", To: []string{"ops@example.com"}, Password: "secret"}, m.ErrInvalidEmail, "Password without username"},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := c.email.Validate()
+			hasErr := (actual != nil)
+			expectErr := (c.expected != nil)
+
+			if hasErr != expectErr || !errors.Is(actual, c.expected) {
+				t.Errorf("Validate(%v), expected=%v actual=%v", c.name, c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestEmailAlertSend(t *testing.T) {
+	t.Parallel()
+
+	host, port, messages := newSMTPServer(t)
+	notice := m.AlertNotice{MonitorName: "web", LastCheckOutput: "connection refused <eof>"}
+
+	cases := []struct {
+		email    m.EmailAlert
+		expected []string
+		name     string
+	}{
+		{
+			m.EmailAlert{},
+			[]string{"Subject: web is down\r\n", "Content-Type: text/plain; charset=utf-8\r\n", "connection refused"},
+			"Default templates",
+		},
+		{
+			m.EmailAlert{Subject: "[minitor] {{.MonitorName}}", Body: "plain {{.LastCheckOutput}}", HTMLBody: "<b>{{.LastCheckOutput}}</b>"},
+			[]string{
+				"Subject: [minitor] web\r\n",
+				"Content-Type: multipart/alternative; boundary=",
+				"Content-Type: text/plain; charset=utf-8\r\n",
+				"plain connection refused <eof>",
+				"Content-Type: text/html; charset=utf-8\r\n",
+				"<b>connection refused &lt;eof&gt;</b>",
+			},
+			"HTML part",
+		},
+	}
+
+	for _, c := range cases {
+		alert := m.Alert{Name: "email", Email: &c.email}
+		alert.Email.Host = host
+		alert.Email.Port = port
+		alert.Email.Security = "none"
+		alert.Email.From = "Minitor <minitor@example.com>"
+		alert.Email.To = []string{"ops@example.com", "dev@example.com"}
+
+		if err := alert.Validate(); err != nil {
+			t.Fatalf("Validate(%v), unexpected error: %v", c.name, err)
+		}
+
+		if err := alert.BuildTemplates(); err != nil {
+			t.Fatalf("BuildTemplates(%v), unexpected error: %v", c.name, err)
+		}
+
+		if _, err := alert.Send(notice); err != nil {
+			t.Fatalf("Send(%v), unexpected error: %v", c.name, err)
+		}
+
+		message := <-messages
+		if message.from != "minitor@example.com" || !m.EqualSliceString(message.to, alert.Email.To) {
+			t.Errorf("Send(%v), unexpected envelope from=%s to=%v", c.name, message.from, message.to)
+		}
+
+		for _, expected := range c.expected {
+			if !strings.Contains(message.data, expected) {
+				t.Errorf("Send(%v), expected message to contain %q, got %q", c.name, expected, message.data)
+			}
+		}
+	}
+}
+
+func TestEmailAlertStartTLSUnsupported(t *testing.T) {
+	t.Parallel()
+
+	host, port, _ := newSMTPServer(t)
+	alert := m.Alert{Name: "email", Email: &m.EmailAlert{
+		Host: host, Port: port, From: "minitor@example.com", To: []string{"ops@example.com"},
+	}}
+
+	if err := alert.BuildTemplates(); err != nil {
+		t.Fatalf("BuildTemplates(), unexpected error: %v", err)
+	}
+
+	if _, err := alert.Send(m.AlertNotice{MonitorName: "web"}); !errors.Is(err, m.ErrAlertFailed) {
+		t.Errorf("Send(), expected=%v actual=%v", m.ErrAlertFailed, err)
+	}
+}
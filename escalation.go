@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+var (
+	ErrInvalidEscalation = errors.New("Invalid escalation configuration")
+	ErrUnknownEscalation = errors.New("Unknown escalation policy")
+)
+
+// EscalationStep is a set of alerts that are added once a Monitor has been down long enough
+type EscalationStep struct {
+	AfterAlerts int     `hcl:"after_alerts,optional"`
+	AfterStr    *string `hcl:"after,optional"`
+	After       time.Duration
+	Alerts      []string `hcl:"alerts"`
+}
+
+// Escalation is a list of steps configured inline on a Monitor
+type Escalation struct {
+	Steps []*EscalationStep `hcl:"step,block"`
+}
+
+// EscalationPolicy is a named Escalation that can be shared by many monitors
+type EscalationPolicy struct {
+	Name  string            `hcl:"name,label"`
+	Steps []*EscalationStep `hcl:"step,block"`
+}
+
+// Init parses the durations of each step
+func (escalation *Escalation) Init() error {
+	for _, step := range escalation.Steps {
+		if step.AfterStr == nil {
+			continue
+		}
+
+		var err error
+
+		step.After, err = time.ParseDuration(*step.AfterStr)
+		if err != nil {
+			return fmt.Errorf("failed to parse escalation step after duration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks that the Escalation is properly configured and returns errors if not
+func (escalation Escalation) Validate() error {
+	var err error
+
+	if len(escalation.Steps) == 0 {
+		err = errors.Join(err, fmt.Errorf("%w: no steps configured", ErrInvalidEscalation))
+	}
+
+	for i, step := range escalation.Steps {
+		hasAfterAlerts := step.AfterAlerts != 0
+		hasAfter := step.AfterStr != nil
+
+		switch {
+		case hasAfterAlerts == hasAfter:
+			err = errors.Join(err, fmt.Errorf(
+				"%w: step %d must have exactly one of after_alerts or after configured",
+				ErrInvalidEscalation,
+				i+1,
+			))
+		case step.AfterAlerts < 0:
+			err = errors.Join(err, fmt.Errorf(
+				"%w: step %d has invalid after_alerts value %d. Must be greater than 0",
+				ErrInvalidEscalation,
+				i+1,
+				step.AfterAlerts,
+			))
+		case step.After < 0:
+			err = errors.Join(err, fmt.Errorf(
+				"%w: step %d has invalid after value %s. Must not be negative",
+				ErrInvalidEscalation,
+				i+1,
+				step.After,
+			))
+		}
+
+		if len(step.Alerts) == 0 {
+			err = errors.Join(err, fmt.Errorf("%w: step %d has no alerts configured", ErrInvalidEscalation, i+1))
+		}
+	}
+
+	return err
+}
+
+// AlertNames returns the alerts of every step reached after the given number of alerts and time down
+func (escalation Escalation) AlertNames(alertCount int, downFor time.Duration) []string {
+	alertNames := []string{}
+
+	for _, step := range escalation.Steps {
+		isReached := (step.AfterStr == nil && alertCount >= step.AfterAlerts) ||
+			(step.AfterStr != nil && downFor >= step.After)
+		if isReached {
+			alertNames = append(alertNames, step.Alerts...)
+		}
+	}
+
+	return alertNames
+}
+
+// IsReachedOnFirstAlert returns true if any step is reached by the first alert of an outage
+func (escalation Escalation) IsReachedOnFirstAlert() bool {
+	for _, step := range escalation.Steps {
+		if (step.AfterStr == nil && step.AfterAlerts <= 1) || (step.AfterStr != nil && step.After == 0) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AllAlertNames returns the alerts of every step
+func (escalation Escalation) AllAlertNames() []string {
+	alertNames := []string{}
+
+	for _, step := range escalation.Steps {
+		alertNames = append(alertNames, step.Alerts...)
+	}
+
+	return alertNames
+}
+
+// mergeAlertNames combines lists of alert names, dropping duplicates while keeping the order
+func mergeAlertNames(lists ...[]string) []string {
+	merged := []string{}
+
+	for _, alertName := range slices.Concat(lists...) {
+		if !slices.Contains(merged, alertName) {
+			merged = append(merged, alertName)
+		}
+	}
+
+	return merged
+}
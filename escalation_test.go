@@ -0,0 +1,86 @@
+package main_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	m "git.iamthefij.com/iamthefij/minitor-go/v2"
+)
+
+func TestEscalationValidate(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		escalation m.Escalation
+		expected   error
+		name       string
+	}{
+		{m.Escalation{Steps: []*m.EscalationStep{{AfterAlerts: 1, Alerts: []string{"chat"}}, {AfterStr: Ptr("1h"), Alerts: []string{"sms"}}}}, nil, "Valid"},
+		{m.Escalation{}, m.ErrInvalidEscalation, "No steps"},
+		{m.Escalation{Steps: []*m.EscalationStep{{Alerts: []string{"chat"}}}}, m.ErrInvalidEscalation, "No threshold"},
+		{m.Escalation{Steps: []*m.EscalationStep{{AfterAlerts: 1, AfterStr: Ptr("1h"), Alerts: []string{"chat"}}}}, m.ErrInvalidEscalation, "Both thresholds"},
+		{m.Escalation{Steps: []*m.EscalationStep{{AfterAlerts: -1, Alerts: []string{"chat"}}}}, m.ErrInvalidEscalation, "Negative after_alerts"},
+		{m.Escalation{Steps: []*m.EscalationStep{{AfterStr: Ptr("-1h"), Alerts: []string{"chat"}}}}, m.ErrInvalidEscalation, "Negative after"},
+		{m.Escalation{Steps: []*m.EscalationStep{{AfterAlerts: 1}}}, m.ErrInvalidEscalation, "No alerts"},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := c.escalation.Init(); err != nil {
+				t.Fatalf("Init(%v), unexpected error: %v", c.name, err)
+			}
+
+			actual := c.escalation.Validate()
+			hasErr := (actual != nil)
+			expectErr := (c.expected != nil)
+
+			if hasErr != expectErr || !errors.Is(actual, c.expected) {
+				t.Errorf("Validate(%v), expected=%v actual=%v", c.name, c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestEscalationAlertNames(t *testing.T) {
+	t.Parallel()
+
+	escalation := m.Escalation{Steps: []*m.EscalationStep{
+		{AfterAlerts: 1, Alerts: []string{"chat"}},
+		{AfterAlerts: 3, Alerts: []string{"sms"}},
+		{AfterStr: Ptr("1h"), Alerts: []string{"team_lead"}},
+	}}
+
+	if err := escalation.Init(); err != nil {
+		t.Fatalf("Init(), unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		alertCount int
+		downFor    time.Duration
+		expected   []string
+		name       string
+	}{
+		{1, time.Minute, []string{"chat"}, "First alert"},
+		{2, time.Minute, []string{"chat"}, "Second alert"},
+		{3, time.Minute, []string{"chat", "sms"}, "Third alert"},
+		{2, 2 * time.Hour, []string{"chat", "team_lead"}, "Long outage"},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := escalation.AlertNames(c.alertCount, c.downFor)
+			if !m.EqualSliceString(actual, c.expected) {
+				t.Errorf("AlertNames(%v), expected=%v actual=%v", c.name, c.expected, actual)
+			}
+		})
+	}
+}
@@ -6,6 +6,8 @@ require (
 	git.iamthefij.com/iamthefij/slog v1.3.0
 	github.com/hashicorp/hcl/v2 v2.11.1
 	github.com/prometheus/client_golang v1.23.2
+	github.com/robfig/cron/v3 v3.0.1
+	golang.org/x/crypto v0.41.0
 )
 
 require (
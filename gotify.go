@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// ErrInvalidGotify indicates that a gotify alert is not properly configured
+var ErrInvalidGotify = errors.New("Invalid gotify configuration")
+
+const (
+	gotifyMinPriority         = 0
+	gotifyMaxPriority         = 10
+	gotifyDefaultPriorityDown = 8
+	gotifyDefaultPriorityUp   = 4
+)
+
+// GotifyAlert is a built in alert type that sends a push notification through a Gotify server
+type GotifyAlert struct {
+	URL        string `hcl:"url"`
+	Token      string `hcl:"token"`
+	Priority   *int   `hcl:"priority,optional"`
+	PriorityUp *int   `hcl:"priority_up,optional"`
+	Click      string `hcl:"click,optional"`
+	Title      string `hcl:"title,optional"`
+	Message    string `hcl:"message,optional"`
+
+	clickTemplate   *template.Template
+	titleTemplate   *template.Template
+	messageTemplate *template.Template
+}
+
+type gotifyMessage struct {
+	Title    string         `json:"title"`
+	Message  string         `json:"message"`
+	Priority int            `json:"priority"`
+	Extras   map[string]any `json:"extras,omitempty"`
+}
+
+// Validate checks that the GotifyAlert is properly configured and returns errors if not
+func (gotify GotifyAlert) Validate() error {
+	var err error
+
+	err = errors.Join(err, validateHTTPURL(ErrInvalidGotify, "url", gotify.URL))
+
+	if gotify.Token == "" {
+		err = errors.Join(err, fmt.Errorf("%w: token is required", ErrInvalidGotify))
+	}
+
+	err = errors.Join(
+		err,
+		validatePriority(ErrInvalidGotify, "priority", gotify.Priority, gotifyMinPriority, gotifyMaxPriority),
+		validatePriority(ErrInvalidGotify, "priority_up", gotify.PriorityUp, gotifyMinPriority, gotifyMaxPriority),
+	)
+
+	return err
+}
+
+// BuildTemplates compiles the click, title and message templates for the GotifyAlert
+func (gotify *GotifyAlert) BuildTemplates(name string, funcs template.FuncMap) error {
+	var err error
+
+	gotify.clickTemplate, err = template.New(name + "-click").Funcs(funcs).Parse(gotify.Click)
+	if err != nil {
+		return err
+	}
+
+	title := gotify.Title
+	if title == "" {
+		title = defaultNoticeTitle
+	}
+
+	gotify.titleTemplate, err = template.New(name + "-title").Funcs(funcs).Parse(title)
+	if err != nil {
+		return err
+	}
+
+	message := gotify.Message
+	if message == "" {
+		message = defaultNoticeMessage
+	}
+
+	gotify.messageTemplate, err = template.New(name + "-message").Funcs(funcs).Parse(message)
+
+	return err
+}
+
+// Send renders the notification and sends it with the application token
+func (gotify GotifyAlert) Send(notice AlertNotice) (string, error) {
+	if gotify.clickTemplate == nil || gotify.titleTemplate == nil || gotify.messageTemplate == nil {
+		return "", fmt.Errorf("No templates compiled for gotify: %w", errNoTemplate)
+	}
+
+	var click, title, message strings.Builder
+
+	if err := gotify.clickTemplate.Execute(&click, notice); err != nil {
+		return "", err
+	}
+
+	if err := gotify.titleTemplate.Execute(&title, notice); err != nil {
+		return "", err
+	}
+
+	if err := gotify.messageTemplate.Execute(&message, notice); err != nil {
+		return "", err
+	}
+
+	payload := gotifyMessage{
+		Title:    title.String(),
+		Message:  message.String(),
+		Priority: noticePriority(notice, gotify.Priority, gotify.PriorityUp, gotifyDefaultPriorityDown, gotifyDefaultPriorityUp),
+	}
+
+	if clickURL := strings.TrimSpace(click.String()); clickURL != "" {
+		payload.Extras = map[string]any{
+			"client::notification": map[string]any{"click": map[string]string{"url": clickURL}},
+		}
+	}
+
+	return postJSON(
+		strings.TrimSuffix(gotify.URL, "/")+"/message",
+		map[string]string{"X-Gotify-Key": gotify.Token},
+		payload,
+	)
+}
@@ -0,0 +1,125 @@
+package main_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	m "git.iamthefij.com/iamthefij/minitor-go/v2"
+)
+
+func TestGotifyAlertValidate(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		gotify   m.GotifyAlert
+		expected error
+		name     string
+	}{
+		{m.GotifyAlert{URL: "https://gotify.example.com", Token: "tk"}, nil, "Valid"},
+		{m.GotifyAlert{URL: "https://gotify.example.com", Token: "tk", Priority: Ptr(10), PriorityUp: Ptr(0)}, nil, "Valid priority"},
+		{m.GotifyAlert{Token: "tk"}, m.ErrInvalidGotify, "No url"},
+		{m.GotifyAlert{URL: "https://gotify.example.com"}, m.ErrInvalidGotify, "No token"},
+		{m.GotifyAlert{URL: "https://gotify.example.com", Token: "tk", Priority: Ptr(11)}, m.ErrInvalidGotify, "Priority too high"},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := c.gotify.Validate()
+			hasErr := (actual != nil)
+			expectErr := (c.expected != nil)
+
+			if hasErr != expectErr || !errors.Is(actual, c.expected) {
+				t.Errorf("Validate(%v), expected=%v actual=%v", c.name, c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestGotifyAlertSend(t *testing.T) {
+	t.Parallel()
+
+	type gotifyRequest struct {
+		path  string
+		token string
+		body  string
+	}
+
+	requests := make(chan gotifyRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		requests <- gotifyRequest{path: r.URL.Path, token: r.Header.Get("X-Gotify-Key"), body: string(body)}
+	}))
+
+	t.Cleanup(server.Close)
+
+	type gotifyPayload struct {
+		Title    string `json:"title"`
+		Message  string `json:"message"`
+		Priority int    `json:"priority"`
+		Extras   struct {
+			Notification struct {
+				Click struct {
+					URL string `json:"url"`
+				} `json:"click"`
+			} `json:"client::notification"` //nolint:tagliatelle
+		} `json:"extras"`
+	}
+
+	cases := []struct {
+		gotify           m.GotifyAlert
+		notice           m.AlertNotice
+		expectedTitle    string
+		expectedPriority int
+		expectedClick    string
+		name             string
+	}{
+		{m.GotifyAlert{}, m.AlertNotice{MonitorName: "web"}, "web is down", 8, "", "Down with defaults"},
+		{m.GotifyAlert{}, m.AlertNotice{MonitorName: "web", IsUp: true}, "web is up", 4, "", "Up with defaults"},
+		{
+			m.GotifyAlert{Priority: Ptr(10), Click: "https://status.example.com/{{.MonitorName}}", Title: "{{.MonitorName}}!"},
+			m.AlertNotice{MonitorName: "web"},
+			"web!",
+			10,
+			"https://status.example.com/web",
+			"Configured",
+		},
+	}
+
+	for _, c := range cases {
+		alert := m.Alert{Name: "gotify", Gotify: &c.gotify}
+		alert.Gotify.URL = server.URL
+		alert.Gotify.Token = "app-token"
+
+		if err := alert.BuildTemplates(); err != nil {
+			t.Fatalf("BuildTemplates(%v), unexpected error: %v", c.name, err)
+		}
+
+		if _, err := alert.Send(c.notice); err != nil {
+			t.Fatalf("Send(%v), unexpected error: %v", c.name, err)
+		}
+
+		request := <-requests
+		if request.path != "/message" || request.token != "app-token" {
+			t.Errorf("Send(%v), unexpected path=%q token=%q", c.name, request.path, request.token)
+		}
+
+		var payload gotifyPayload
+		if err := json.Unmarshal([]byte(request.body), &payload); err != nil {
+			t.Fatalf("Send(%v), invalid json body %q: %v", c.name, request.body, err)
+		}
+
+		if payload.Title != c.expectedTitle || payload.Priority != c.expectedPriority ||
+			payload.Extras.Notification.Click.URL != c.expectedClick || payload.Message == "" {
+			t.Errorf("Send(%v), unexpected payload %q", c.name, request.body)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// HostCheck is a config driven check of local resource usage
+type HostCheck struct {
+	Filesystems               []*FilesystemCheck `hcl:"filesystem,block"`
+	MinMemoryAvailableMB      *float64           `hcl:"min_memory_available_mb,optional"`
+	MinMemoryAvailablePercent *float64           `hcl:"min_memory_available_percent,optional"`
+	MaxSwapUsedPercent        *float64           `hcl:"max_swap_used_percent,optional"`
+	MaxLoad1                  *float64           `hcl:"max_load1,optional"`
+	MaxLoad5                  *float64           `hcl:"max_load5,optional"`
+	MaxLoad15                 *float64           `hcl:"max_load15,optional"`
+
+	readings HostReadings
+}
+
+// FilesystemCheck sets usage thresholds for a single mount point
+type FilesystemCheck struct {
+	Mount                string   `hcl:"mount,label"`
+	MaxUsedPercent       *float64 `hcl:"max_used_percent,optional"`
+	MaxInodesUsedPercent *float64 `hcl:"max_inodes_used_percent,optional"`
+	MinAvailableMB       *float64 `hcl:"min_available_mb,optional"`
+}
+
+// HostReadings contains the resource values read during the last HostCheck
+type HostReadings struct {
+	Filesystems          []FilesystemReading
+	MemoryAvailableBytes float64
+	MemoryTotalBytes     float64
+	SwapUsedPercent      float64
+	Load1                float64
+	Load5                float64
+	Load15               float64
+
+	hasMemory bool
+	hasLoad   bool
+}
+
+// FilesystemReading contains the usage values of a single mount point
+type FilesystemReading struct {
+	Mount             string
+	UsedPercent       float64
+	InodesUsedPercent float64
+	AvailableBytes    float64
+}
+
+// Init verifies that the HostCheck has something to check
+func (check *HostCheck) Init() error {
+	if len(check.Filesystems) == 0 && !check.needsMemory() && !check.needsLoad() {
+		return fmt.Errorf("%w: host check has no filesystem, memory, swap or load thresholds", ErrInvalidMonitor)
+	}
+
+	return nil
+}
+
+// Readings returns the values read during the last check
+func (check HostCheck) Readings() HostReadings {
+	return check.readings
+}
+
+// Check reads resource usage from /proc and statfs and compares it against the configured thresholds
+func (check *HostCheck) Check() (string, error) {
+	var (
+		output strings.Builder
+		err    error
+	)
+
+	readings := HostReadings{}
+
+	for _, filesystem := range check.Filesystems {
+		reading, statErr := readFilesystem(filesystem.Mount)
+		if statErr != nil {
+			fmt.Fprintf(&output, "filesystem %s: %v\n", filesystem.Mount, statErr)
+			err = errors.Join(err, fmt.Errorf("%w: %w", ErrCheckFailed, statErr))
+
+			continue
+		}
+
+		readings.Filesystems = append(readings.Filesystems, reading)
+
+		fmt.Fprintf(
+			&output, "filesystem %s: used=%.1f%% inodes=%.1f%% available=%.0fMB\n",
+			reading.Mount, reading.UsedPercent, reading.InodesUsedPercent, reading.AvailableBytes/bytesPerMiB,
+		)
+
+		err = errors.Join(
+			err,
+			checkMax("filesystem "+reading.Mount+" used percent", reading.UsedPercent, filesystem.MaxUsedPercent),
+			checkMax("filesystem "+reading.Mount+" inodes used percent", reading.InodesUsedPercent, filesystem.MaxInodesUsedPercent),
+			checkMin("filesystem "+reading.Mount+" available MB", reading.AvailableBytes/bytesPerMiB, filesystem.MinAvailableMB),
+		)
+	}
+
+	if check.needsMemory() {
+		meminfo, memErr := readMeminfo()
+		if memErr != nil {
+			// Keep the filesystem readings so stale values aren't exported
+			check.readings = readings
+
+			return output.String(), memErr
+		}
+
+		readings.hasMemory = true
+		readings.MemoryTotalBytes = meminfo["MemTotal"]
+		readings.MemoryAvailableBytes = meminfo["MemAvailable"]
+
+		if swapTotal := meminfo["SwapTotal"]; swapTotal > 0 {
+			readings.SwapUsedPercent = (swapTotal - meminfo["SwapFree"]) / swapTotal * percentMultiple
+		}
+
+		memoryAvailablePercent := 0.0
+		if readings.MemoryTotalBytes > 0 {
+			memoryAvailablePercent = readings.MemoryAvailableBytes / readings.MemoryTotalBytes * percentMultiple
+		}
+
+		fmt.Fprintf(
+			&output, "memory: available=%.0fMB (%.1f%%) swap used=%.1f%%\n",
+			readings.MemoryAvailableBytes/bytesPerMiB, memoryAvailablePercent, readings.SwapUsedPercent,
+		)
+
+		err = errors.Join(
+			err,
+			checkMin("memory available MB", readings.MemoryAvailableBytes/bytesPerMiB, check.MinMemoryAvailableMB),
+			checkMin("memory available percent", memoryAvailablePercent, check.MinMemoryAvailablePercent),
+			checkMax("swap used percent", readings.SwapUsedPercent, check.MaxSwapUsedPercent),
+		)
+	}
+
+	if check.needsLoad() {
+		loadErr := readLoadAverage(&readings)
+		if loadErr != nil {
+			check.readings = readings
+
+			return output.String(), loadErr
+		}
+
+		readings.hasLoad = true
+		fmt.Fprintf(&output, "load: %.2f %.2f %.2f\n", readings.Load1, readings.Load5, readings.Load15)
+
+		err = errors.Join(
+			err,
+			checkMax("load1", readings.Load1, check.MaxLoad1),
+			checkMax("load5", readings.Load5, check.MaxLoad5),
+			checkMax("load15", readings.Load15, check.MaxLoad15),
+		)
+	}
+
+	check.readings = readings
+
+	return output.String(), err
+}
+
+func (check HostCheck) needsMemory() bool {
+	return check.MinMemoryAvailableMB != nil || check.MinMemoryAvailablePercent != nil || check.MaxSwapUsedPercent != nil
+}
+
+func (check HostCheck) needsLoad() bool {
+	return check.MaxLoad1 != nil || check.MaxLoad5 != nil || check.MaxLoad15 != nil
+}
+
+func checkMax(name string, value float64, limit *float64) error {
+	if limit != nil && value > *limit {
+		return fmt.Errorf("%w: %s is %.2f, expected at most %.2f", ErrCheckFailed, name, value, *limit)
+	}
+
+	return nil
+}
+
+func checkMin(name string, value float64, limit *float64) error {
+	if limit != nil && value < *limit {
+		return fmt.Errorf("%w: %s is %.2f, expected at least %.2f", ErrCheckFailed, name, value, *limit)
+	}
+
+	return nil
+}
+
+// readMeminfo returns the values from /proc/meminfo in bytes
+func readMeminfo() (map[string]float64, error) {
+	content, err := os.ReadFile(procPath + "/meminfo")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read meminfo: %w", err)
+	}
+
+	meminfo := map[string]float64{}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		field, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		kib, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(value), " kB"), 64)
+		if err != nil {
+			continue
+		}
+
+		meminfo[field] = kib * bytesPerKiB
+	}
+
+	return meminfo, nil
+}
+
+func readLoadAverage(readings *HostReadings) error {
+	content, err := os.ReadFile(procPath + "/loadavg")
+	if err != nil {
+		return fmt.Errorf("failed to read loadavg: %w", err)
+	}
+
+	fields := strings.Fields(string(content))
+	if len(fields) < 3 { //nolint:mnd
+		return fmt.Errorf("%w: unexpected loadavg format %q", ErrCheckFailed, content)
+	}
+
+	for i, load := range []*float64{&readings.Load1, &readings.Load5, &readings.Load15} {
+		*load, err = strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse loadavg: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+//go:build linux || darwin
+
+package main
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// readFilesystem returns the space and inode usage of the filesystem at mount
+func readFilesystem(mount string) (FilesystemReading, error) {
+	reading := FilesystemReading{Mount: mount}
+
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(mount, &stat); err != nil {
+		return reading, fmt.Errorf("failed to stat filesystem %s: %w", mount, err)
+	}
+
+	blockSize := float64(stat.Bsize)
+	total := float64(stat.Blocks) * blockSize
+	free := float64(stat.Bfree) * blockSize
+	reading.AvailableBytes = float64(stat.Bavail) * blockSize
+
+	// Match df by calculating usage against space available to unprivileged users
+	if usable := total - free + reading.AvailableBytes; usable > 0 {
+		reading.UsedPercent = (total - free) / usable * percentMultiple
+	}
+
+	if stat.Files > 0 {
+		reading.InodesUsedPercent = float64(stat.Files-stat.Ffree) / float64(stat.Files) * percentMultiple
+	}
+
+	return reading, nil
+}
@@ -0,0 +1,21 @@
+//go:build !linux && !darwin
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+var errUnsupportedPlatform = errors.New("unsupported platform")
+
+// readFilesystem is not supported on this platform, so filesystem checks always fail
+func readFilesystem(mount string) (FilesystemReading, error) {
+	return FilesystemReading{Mount: mount}, fmt.Errorf(
+		"failed to stat filesystem %s: %w: %s",
+		mount,
+		errUnsupportedPlatform,
+		runtime.GOOS,
+	)
+}
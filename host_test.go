@@ -0,0 +1,83 @@
+package main_test
+
+import (
+	"testing"
+
+	m "git.iamthefij.com/iamthefij/minitor-go/v2"
+)
+
+func TestHostCheck(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		check     m.HostCheck
+		expectErr bool
+		name      string
+	}{
+		{m.HostCheck{Filesystems: []*m.FilesystemCheck{{Mount: "/", MaxUsedPercent: Ptr(100.0), MaxInodesUsedPercent: Ptr(100.0)}}}, false, "Filesystem below threshold"},
+		{m.HostCheck{Filesystems: []*m.FilesystemCheck{{Mount: "/", MinAvailableMB: Ptr(1e12)}}}, true, "Filesystem low available"},
+		{m.HostCheck{Filesystems: []*m.FilesystemCheck{{Mount: "/does-not-exist"}}}, true, "Missing filesystem"},
+		{m.HostCheck{MinMemoryAvailableMB: Ptr(1.0), MinMemoryAvailablePercent: Ptr(0.1), MaxSwapUsedPercent: Ptr(100.0)}, false, "Memory available"},
+		{m.HostCheck{MinMemoryAvailableMB: Ptr(1e12)}, true, "Memory not available"},
+		{m.HostCheck{MaxLoad1: Ptr(1e6), MaxLoad5: Ptr(1e6), MaxLoad15: Ptr(1e6)}, false, "Load below threshold"},
+		{m.HostCheck{MaxLoad15: Ptr(-1.0)}, true, "Load above threshold"},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := c.check.Init(); err != nil {
+				t.Fatalf("Init(%v), unexpected error: %v", c.name, err)
+			}
+
+			output, err := c.check.Check()
+			hasErr := (err != nil)
+
+			if hasErr != c.expectErr {
+				t.Errorf("Check(%v), expected_error=%t actual=%v output=%s", c.name, c.expectErr, err, output)
+			}
+		})
+	}
+}
+
+func TestHostCheckReadings(t *testing.T) {
+	t.Parallel()
+
+	check := m.HostCheck{
+		Filesystems:          []*m.FilesystemCheck{{Mount: "/"}},
+		MinMemoryAvailableMB: Ptr(0.0),
+	}
+
+	if err := check.Init(); err != nil {
+		t.Fatalf("Init, unexpected error: %v", err)
+	}
+
+	if _, err := check.Check(); err != nil {
+		t.Fatalf("Check, unexpected error: %v", err)
+	}
+
+	readings := check.Readings()
+
+	if len(readings.Filesystems) != 1 || readings.Filesystems[0].Mount != "/" {
+		t.Errorf("Readings(filesystems), expected=[/] actual=%v", readings.Filesystems)
+	}
+
+	if readings.MemoryTotalBytes <= 0 {
+		t.Errorf("Readings(memory), expected total memory to be read, actual=%v", readings.MemoryTotalBytes)
+	}
+
+	// Exporting readings should not panic
+	m.Metrics.SetHostReadings("host", readings)
+}
+
+func TestHostCheckInit(t *testing.T) {
+	t.Parallel()
+
+	check := m.HostCheck{}
+	if err := check.Init(); err == nil {
+		t.Errorf("Init(empty), expected error")
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// maxLogfileOutputLines limits how many matching lines are included in the check output
+const maxLogfileOutputLines = 50
+
+// LogfileCheck is a config driven check that counts lines matching a pattern in a log file
+type LogfileCheck struct {
+	Path          string `hcl:"path"`
+	Pattern       string `hcl:"pattern"`
+	WindowStr     string `hcl:"window"`
+	MaxMatches    int    `hcl:"max_matches,optional"`
+	ReadFromStart bool   `hcl:"read_from_start,optional"`
+
+	Window        time.Duration
+	patternRegexp *regexp.Regexp
+	fileInfo      os.FileInfo
+	offset        int64
+	matches       []logfileMatch
+}
+
+type logfileMatch struct {
+	seen time.Time
+	line string
+}
+
+// Init compiles the pattern and parses the window duration for the LogfileCheck
+func (check *LogfileCheck) Init() error {
+	var err error
+
+	check.patternRegexp, err = regexp.Compile(check.Pattern)
+	if err != nil {
+		return fmt.Errorf("%w: invalid logfile pattern: %w", ErrInvalidMonitor, err)
+	}
+
+	check.Window, err = time.ParseDuration(check.WindowStr)
+	if err != nil {
+		return fmt.Errorf("failed to parse logfile window duration: %w", err)
+	}
+
+	if check.MaxMatches < 0 {
+		return fmt.Errorf("%w: logfile max_matches must not be negative", ErrInvalidMonitor)
+	}
+
+	return nil
+}
+
+// Check reads lines appended to the log file since the last check and fails if more
+// than MaxMatches lines matched the pattern within the window
+func (check *LogfileCheck) Check() (string, error) {
+	if err := check.readNewLines(); err != nil {
+		return "", err
+	}
+
+	// Drop matches that have fallen out of the window
+	cutoff := time.Now().Add(-check.Window)
+	for len(check.matches) > 0 && check.matches[0].seen.Before(cutoff) {
+		check.matches = check.matches[1:]
+	}
+
+	var output strings.Builder
+
+	fmt.Fprintf(&output, "%d lines matching %q in %s within %s\n", len(check.matches), check.Pattern, check.Path, check.Window)
+
+	start := max(0, len(check.matches)-maxLogfileOutputLines)
+	for _, match := range check.matches[start:] {
+		output.WriteString(match.line)
+		output.WriteString("\n")
+	}
+
+	if len(check.matches) > check.MaxMatches {
+		return output.String(), fmt.Errorf(
+			"%w: %d lines matched %q, expected at most %d",
+			ErrCheckFailed, len(check.matches), check.Pattern, check.MaxMatches,
+		)
+	}
+
+	return output.String(), nil
+}
+
+// readNewLines reads complete lines since the last offset, starting over when the file is rotated or truncated
+func (check *LogfileCheck) readNewLines() error {
+	file, err := os.Open(check.Path)
+	if err != nil {
+		return fmt.Errorf("%w: failed to open log file: %w", ErrCheckFailed, err)
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("%w: failed to stat log file: %w", ErrCheckFailed, err)
+	}
+
+	switch {
+	case check.fileInfo == nil:
+		// First read starts at the end of the file, like tail, unless configured otherwise
+		if !check.ReadFromStart {
+			check.offset = fileInfo.Size()
+		}
+	case !os.SameFile(check.fileInfo, fileInfo):
+		// File was rotated and replaced with a new one
+		check.offset = 0
+	case fileInfo.Size() < check.offset:
+		// File was truncated in place
+		check.offset = 0
+	}
+
+	check.fileInfo = fileInfo
+
+	if _, err = file.Seek(check.offset, io.SeekStart); err != nil {
+		return fmt.Errorf("%w: failed to seek log file: %w", ErrCheckFailed, err)
+	}
+
+	now := time.Now()
+	reader := bufio.NewReader(file)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// Leave partially written lines to be read on the next check
+			break
+		} else if err != nil {
+			return fmt.Errorf("%w: failed to read log file: %w", ErrCheckFailed, err)
+		}
+
+		check.offset += int64(len(line))
+
+		line = bytes.TrimRight(line, "\r\n")
+		if check.patternRegexp.Match(line) {
+			check.matches = append(check.matches, logfileMatch{seen: now, line: string(line)})
+		}
+	}
+
+	return nil
+}
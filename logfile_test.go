@@ -0,0 +1,151 @@
+package main_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	m "git.iamthefij.com/iamthefij/minitor-go/v2"
+)
+
+func appendLog(t *testing.T, path string, content string) {
+	t.Helper()
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatalf("failed to open log file: %v", err)
+	}
+	defer file.Close()
+
+	if _, err = file.WriteString(content); err != nil {
+		t.Fatalf("failed to write log file: %v", err)
+	}
+}
+
+func TestLogfileCheck(t *testing.T) {
+	t.Parallel()
+
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	appendLog(t, logPath, "ERROR before minitor started\n")
+
+	check := m.LogfileCheck{Path: logPath, Pattern: "ERROR", WindowStr: "10m", MaxMatches: 2}
+	if err := check.Init(); err != nil {
+		t.Fatalf("Init, unexpected error: %v", err)
+	}
+
+	steps := []struct {
+		write     string
+		replace   bool
+		expectErr bool
+		contains  string
+		name      string
+	}{
+		{"", false, false, "0 lines matching", "Existing lines are skipped"},
+		{"INFO ok\nERROR first\n", false, false, "ERROR first", "Below threshold"},
+		{"ERROR second\nERROR part", false, false, "ERROR second", "Partial line is not counted"},
+		{"ial third\n", false, true, "ERROR partial third", "Above threshold"},
+		{"INFO new file\n", true, true, "3 lines matching", "Rotation keeps window"},
+		{"ERROR after rotation\n", false, true, "ERROR after rotation", "Rotated file is read from start"},
+	}
+
+	for _, step := range steps {
+		if step.replace {
+			rotatedPath := logPath + ".1"
+			if err := os.Rename(logPath, rotatedPath); err != nil {
+				t.Fatalf("failed to rotate log file: %v", err)
+			}
+		}
+
+		if step.write != "" {
+			appendLog(t, logPath, step.write)
+		}
+
+		output, err := check.Check()
+		hasErr := (err != nil)
+
+		if hasErr != step.expectErr {
+			t.Errorf("Check(%v), expected_error=%t actual=%v output=%s", step.name, step.expectErr, err, output)
+		}
+
+		if !strings.Contains(output, step.contains) {
+			t.Errorf("Check(%v), expected output to contain %q, actual=%s", step.name, step.contains, output)
+		}
+	}
+}
+
+func TestLogfileCheckTruncateAndWindow(t *testing.T) {
+	t.Parallel()
+
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	appendLog(t, logPath, "ERROR one\nERROR two\n")
+
+	check := m.LogfileCheck{Path: logPath, Pattern: "^ERROR", WindowStr: "200ms", ReadFromStart: true}
+	if err := check.Init(); err != nil {
+		t.Fatalf("Init, unexpected error: %v", err)
+	}
+
+	if _, err := check.Check(); err == nil {
+		t.Errorf("Check(read from start), expected error")
+	}
+
+	// Matches expire once they are outside of the window
+	time.Sleep(300 * time.Millisecond)
+
+	if output, err := check.Check(); err != nil {
+		t.Errorf("Check(window expired), unexpected error: %v output=%s", err, output)
+	}
+
+	// Truncate and write a shorter file so the new line is found from the start
+	if err := os.WriteFile(logPath, []byte("ERROR\n"), 0o600); err != nil {
+		t.Fatalf("failed to truncate log file: %v", err)
+	}
+
+	if output, err := check.Check(); err == nil || !strings.Contains(output, "1 lines matching") {
+		t.Errorf("Check(truncated), expected a single match, actual err=%v output=%s", err, output)
+	}
+}
+
+func TestLogfileCheckInit(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		check     m.LogfileCheck
+		expectErr bool
+		name      string
+	}{
+		{m.LogfileCheck{Path: "app.log", Pattern: "ERROR", WindowStr: "1m"}, false, "Valid"},
+		{m.LogfileCheck{Path: "app.log", Pattern: "(", WindowStr: "1m"}, true, "Invalid pattern"},
+		{m.LogfileCheck{Path: "app.log", Pattern: "ERROR", WindowStr: "a while"}, true, "Invalid window"},
+		{m.LogfileCheck{Path: "app.log", Pattern: "ERROR", WindowStr: "1m", MaxMatches: -1}, true, "Negative max matches"},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := c.check.Init()
+			hasErr := (err != nil)
+
+			if hasErr != c.expectErr {
+				t.Errorf("Init(%v), expected_error=%t actual=%v", c.name, c.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestLogfileCheckMissingFile(t *testing.T) {
+	t.Parallel()
+
+	check := m.LogfileCheck{Path: "./does-not-exist.log", Pattern: "ERROR", WindowStr: "1m"}
+	if err := check.Init(); err != nil {
+		t.Fatalf("Init, unexpected error: %v", err)
+	}
+
+	if _, err := check.Check(); err == nil {
+		t.Errorf("Check(missing file), expected error")
+	}
+}
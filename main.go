@@ -4,6 +4,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -17,6 +18,18 @@ var (
 	MetricsPort = 8080
 	// Metrics contains all active metrics
 	Metrics = NewMetrics()
+	// ServeAPIEnabled will track whether or not we want to serve the silence and status API
+	ServeAPIEnabled = false
+	// APIAddress is the address to serve the silence and status API on
+	APIAddress = "127.0.0.1"
+	// APIPort is the port to serve the silence and status API on
+	APIPort = 8081
+	// SilencesPath is the file that silences are persisted to
+	SilencesPath = "silences.json"
+	// Silences contains all runtime silences
+	Silences = NewSilenceStore()
+	// Status contains the latest status of each monitor
+	Status = NewStatusTracker()
 
 	// version of minitor being run
 	version = "dev"
@@ -24,22 +37,57 @@ var (
 	errUnknownAlert = errors.New("unknown alert")
 )
 
-func SendAlerts(config *Config, monitor *Monitor, alertNotice *AlertNotice) error {
+// SendAlerts sends the notice through the alerts of the monitor. It returns whether any alert was
+// sent or queued, as notices can be dropped by silences and rate limits
+func SendAlerts(config *Config, monitor *Monitor, alertNotice *AlertNotice) (bool, error) {
 	slog.Debugf("Received an alert notice from %s", alertNotice.MonitorName)
-	alertNames := monitor.GetAlertNames(alertNotice.IsUp)
+	var alertNames []string
+
+	switch {
+	case alertNotice.IsFlapping:
+		alertNames = monitor.AlertFlapping
+	case alertNotice.IsUp:
+		alertNames = monitor.GetAlertNames(true)
+	default:
+		alertNames = monitor.GetDownAlertNames(alertNotice.AlertCount)
+	}
 
-	if alertNames == nil {
+	if len(alertNames) == 0 {
 		// This should only happen for a recovery alert. AlertDown is validated not empty
 		slog.Warningf(
 			"Received alert, but no alert mechanisms exist. MonitorName=%s IsUp=%t",
 			alertNotice.MonitorName, alertNotice.IsUp,
 		)
 
-		return nil
+		return false, nil
 	}
 
+	if silence, ok := Silences.Find(monitor.Name, time.Now()); ok {
+		slog.Infof(
+			"Silencing alert for %s because of silence %s by %s: %s",
+			alertNotice.MonitorName, silence.ID, silence.Author, silence.Comment,
+		)
+
+		return false, nil
+	}
+
+	isSent := false
+
 	for _, alertName := range alertNames {
 		if alert, ok := config.GetAlert(alertName); ok {
+			if alert.IsGrouped() {
+				slog.Debugf("Queuing alert %s for %s", alert.Name, alertNotice.MonitorName)
+				alert.Queue(*alertNotice, time.Now())
+
+				isSent = true
+
+				continue
+			}
+
+			if !AllowAlert(config, alert, 1, time.Now()) {
+				continue
+			}
+
 			output, err := alert.Send(*alertNotice)
 			if err != nil {
 				slog.Errorf(
@@ -49,35 +97,99 @@ func SendAlerts(config *Config, monitor *Monitor, alertNotice *AlertNotice) erro
 					output,
 				)
 
-				return err
+				return isSent, err
 			}
 
+			isSent = true
+
 			// Count alert metrics
 			Metrics.CountAlert(monitor.Name, alert.Name)
 		} else {
 			// This case should never actually happen since we validate against it
 			slog.Errorf("Unknown alert for monitor %s: %s", alertNotice.MonitorName, alertName)
 
-			return fmt.Errorf("unknown alert for monitor %s: %s: %w", alertNotice.MonitorName, alertName, errUnknownAlert)
+			return isSent, fmt.Errorf("unknown alert for monitor %s: %s: %w", alertNotice.MonitorName, alertName, errUnknownAlert)
 		}
 	}
 
-	return nil
+	return isSent, nil
+}
+
+// SuppressReason returns a reason why an alert notice should not be sent, or an empty string if it should be sent
+func SuppressReason(monitor *Monitor, alertNotice *AlertNotice) string {
+	silence, isSilenced := Silences.Find(monitor.Name, time.Now())
+
+	switch {
+	case monitor.InMaintenance():
+		return "it is in a maintenance window"
+	case isSilenced:
+		return fmt.Sprintf("of silence %s by %s: %s", silence.ID, silence.Author, silence.Comment)
+	case alertNotice.IsFlapping:
+		return ""
+	case !alertNotice.IsUp && monitor.IsUnreachable():
+		return "a monitor it depends on is down"
+	case alertNotice.IsUp && !monitor.downAlertSent:
+		return "no down alert was sent"
+	}
+
+	return ""
 }
 
 func CheckMonitors(config *Config) error {
 	// TODO: Run this in goroutines and capture exceptions
+	// Monitors are sorted so that dependencies are checked before their dependents
 	for _, monitor := range config.Monitors {
 		if monitor.ShouldCheck() {
 			success, alertNotice := monitor.Check()
+			monitor.isUnreachable = config.IsUnreachable(monitor)
+			monitor.inMaintenance = config.InMaintenance(monitor, time.Now())
+
+			switch {
+			case alertNotice == nil && monitor.IsFlapping() && !monitor.flappingAlertSent:
+				// A flapping alert was suppressed earlier, so try again in case it no longer is
+				alertNotice = monitor.createFlappingNotice()
+			case alertNotice == nil && !success && !monitor.IsUp() && !monitor.downAlertSent && !monitor.IsFlapping():
+				// A down alert was suppressed earlier, so try again in case it no longer is
+				alertNotice = monitor.createAlertNotice(false)
+			}
+
+			if alertNotice != nil {
+				if reason := SuppressReason(monitor, alertNotice); reason != "" {
+					slog.Infof("Suppressing alert for %s because %s", monitor.Name, reason)
+
+					// The outage is over even though the recovery was not sent
+					if alertNotice.IsUp && !alertNotice.IsFlapping {
+						monitor.downAlertSent = false
+					}
+
+					alertNotice = nil
+				} else if alertNotice.IsFlapping {
+					monitor.flappingAlertSent = true
+				}
+			}
+
 			hasAlert := alertNotice != nil
 
 			// Track status metrics
 			Metrics.SetMonitorStatus(monitor.Name, monitor.IsUp())
+			Metrics.SetMonitorFlapping(monitor.Name, monitor.IsFlapping())
+			Metrics.SetMonitorMaintenance(monitor.Name, monitor.InMaintenance())
 			Metrics.CountCheck(monitor.Name, success, monitor.LastCheckMilliseconds(), hasAlert)
+			Metrics.CountCheckAttempts(monitor.Name, monitor.LastCheckAttempts())
+			Status.SetMonitor(monitor)
+
+			if monitor.Host != nil {
+				Metrics.SetHostReadings(monitor.Name, monitor.Host.Readings())
+			}
 
 			if alertNotice != nil {
-				err := SendAlerts(config, monitor, alertNotice)
+				isSent, err := SendAlerts(config, monitor, alertNotice)
+
+				// Only track outages that were alerted, or failed loudly, so recoveries match a down alert
+				if !alertNotice.IsFlapping {
+					monitor.downAlertSent = !alertNotice.IsUp && (isSent || err != nil || monitor.downAlertSent)
+				}
+
 				// If there was an error in sending an alert, exit early and bubble it up
 				if err != nil {
 					return err
@@ -86,6 +198,156 @@ func CheckMonitors(config *Config) error {
 		}
 	}
 
+	if err := SendAlertGroups(config, time.Now()); err != nil {
+		return err
+	}
+
+	if err := ResendFiringAlerts(config, time.Now()); err != nil {
+		return err
+	}
+
+	return SendRateLimitNotices(config, time.Now())
+}
+
+// SendAlertGroups sends the queued notices of grouped alerts that are due
+func SendAlertGroups(config *Config, now time.Time) error {
+	for _, alert := range config.Alerts {
+		if !alert.IsGroupDue(now) {
+			continue
+		}
+
+		notices := alert.TakeGroup(now)
+		if !AllowAlert(config, alert, len(notices), now) {
+			continue
+		}
+
+		output, err := alert.SendGroup(notices)
+		if err != nil {
+			slog.Errorf(
+				"Alert '%s' failed. result=%v: output=%s",
+				alert.Name,
+				err,
+				output,
+			)
+
+			return err
+		}
+
+		// Count alert metrics
+		for _, notice := range notices {
+			Metrics.CountAlert(notice.MonitorName, alert.Name)
+		}
+	}
+
+	return nil
+}
+
+// ResendFiringAlerts repeats notices for alerts that need them while monitors are down
+func ResendFiringAlerts(config *Config, now time.Time) error {
+	upMonitors := map[string]bool{}
+
+	for _, monitor := range config.Monitors {
+		if monitor.IsUp() {
+			upMonitors[monitor.Name] = true
+		}
+	}
+
+	for _, alert := range config.Alerts {
+		output, err := alert.Resend(now, upMonitors)
+		if err != nil {
+			slog.Errorf(
+				"Alert '%s' failed. result=%v: output=%s",
+				alert.Name,
+				err,
+				output,
+			)
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// namedRateLimit pairs a rate limit with a name used in logs and metrics
+type namedRateLimit struct {
+	name  string
+	limit *RateLimit
+}
+
+// AllowAlert checks the rate limits of the alert and the global rate limit. If neither limit has
+// been reached, the notices are counted against both. Otherwise they are counted as suppressed
+func AllowAlert(config *Config, alert *Alert, noticeCount int, now time.Time) bool {
+	// The fallback alert reports on rate limits, so it is never limited itself
+	if alert.Name == config.RateLimitAlert {
+		return true
+	}
+
+	limits := []namedRateLimit{{"alert", alert.RateLimit}, {"global", config.RateLimit}}
+
+	for _, limit := range limits {
+		if limit.limit != nil && limit.limit.IsLimited(now) {
+			slog.Warningf("Suppressing alert %s because the %s rate limit was reached", alert.Name, limit.name)
+			limit.limit.Suppress(noticeCount)
+			Metrics.CountSuppressedAlerts(alert.Name, limit.name, noticeCount)
+
+			return false
+		}
+	}
+
+	for _, limit := range limits {
+		if limit.limit != nil {
+			limit.limit.Record(now)
+		}
+	}
+
+	return true
+}
+
+// SendRateLimitNotices sends a notice through the rate_limit_alert for each rate limit that
+// suppressed alerts during its last period
+func SendRateLimitNotices(config *Config, now time.Time) error {
+	limits := []namedRateLimit{{"global", config.RateLimit}}
+	for _, alert := range config.Alerts {
+		limits = append(limits, namedRateLimit{"alert " + alert.Name, alert.RateLimit})
+	}
+
+	for _, limit := range limits {
+		if limit.limit == nil {
+			continue
+		}
+
+		suppressed := limit.limit.TakeSuppressed(now)
+		if suppressed == 0 {
+			continue
+		}
+
+		message := fmt.Sprintf("rate limit reached, %d alerts suppressed", suppressed)
+
+		fallback, ok := config.GetAlert(config.RateLimitAlert)
+		if !ok {
+			slog.Warningf("Rate limit for %s: %s", limit.name, message)
+
+			continue
+		}
+
+		output, err := fallback.Send(AlertNotice{
+			MonitorName:     "Rate limit for " + limit.name,
+			LastCheckOutput: message,
+			SuppressedCount: suppressed,
+		})
+		if err != nil {
+			slog.Errorf(
+				"Alert '%s' failed. result=%v: output=%s",
+				fallback.Name,
+				err,
+				output,
+			)
+
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -118,6 +380,13 @@ func SendStartupAlerts(config *Config, alertNames []string) error {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "silence" {
+		err := RunSilenceCommand(os.Args[2:], os.Stdout)
+		slog.OnErrFatalf(err, "Error running silence command")
+
+		return
+	}
+
 	showVersion := flag.Bool("version", false, "Display the version of minitor and exit")
 	configPath := flag.String("config", "config.hcl", "Alternate configuration path (default: config.hcl)")
 	startupAlerts := flag.String("startup-alerts", "", "List of alerts to run on startup. This can help determine unhealthy alerts early on. (default \"\")")
@@ -125,6 +394,10 @@ func main() {
 	flag.BoolVar(&slog.DebugLevel, "debug", false, "Enables debug logs (default: false)")
 	flag.BoolVar(&ExportMetrics, "metrics", false, "Enables prometheus metrics exporting (default: false)")
 	flag.IntVar(&MetricsPort, "metrics-port", MetricsPort, "The port that Prometheus metrics should be exported on, if enabled. (default: 8080)")
+	flag.BoolVar(&ServeAPIEnabled, "api", false, "Enables the silence and status API (default: false)")
+	flag.StringVar(&APIAddress, "api-address", APIAddress, "The address that the silence and status API should be served on, if enabled. (default: 127.0.0.1)")
+	flag.IntVar(&APIPort, "api-port", APIPort, "The port that the silence and status API should be served on, if enabled. (default: 8081)")
+	flag.StringVar(&SilencesPath, "silences-file", SilencesPath, "File that silences are persisted to (default: silences.json)")
 	flag.Parse()
 
 	// Print version if flag is provided
@@ -145,6 +418,17 @@ func main() {
 		go ServeMetrics()
 	}
 
+	// Restore silences from a previous run
+	err = Silences.Load(SilencesPath)
+	slog.OnErrFatalf(err, "Error loading silences")
+
+	// Serve silence and status API, if specified
+	if ServeAPIEnabled {
+		slog.Infof("Serving silence and status API on %s port %d", APIAddress, APIPort)
+
+		go ServeAPI()
+	}
+
 	if *startupAlerts != "" {
 		alertNames := strings.Split(*startupAlerts, ",")
 
@@ -1,7 +1,11 @@
 package main_test
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	m "git.iamthefij.com/iamthefij/minitor-go/v2"
 )
@@ -125,6 +129,614 @@ func TestCheckMonitors(t *testing.T) {
 	}
 }
 
+// TestCheckMonitorsDependencies tests that down alerts for monitors with a down parent are suppressed
+func TestCheckMonitorsDependencies(t *testing.T) {
+	t.Parallel()
+
+	alertLog := filepath.Join(t.TempDir(), "alerts.log")
+	config := m.Config{
+		CheckIntervalStr: "1s",
+		Monitors: []*m.Monitor{
+			{Name: "Child", ShellCommand: "false", AlertDown: []string{"log"}, AlertUp: []string{"log"}, DependsOn: []string{"Parent"}},
+			{Name: "Parent", ShellCommand: "false", AlertDown: []string{"log"}, AlertUp: []string{"log"}},
+		},
+		Alerts: []*m.Alert{{
+			Name:         "log",
+			ShellCommand: "echo '{{.MonitorName}} up={{.IsUp}}' >> " + alertLog,
+		}},
+	}
+
+	if err := config.Init(); err != nil {
+		t.Fatalf("checkMonitors(dependencies): unexpected error reading config: %v", err)
+	}
+
+	// Both down, then both recover
+	for _, shellCmd := range []string{"false", "true"} {
+		for _, monitor := range config.Monitors {
+			monitor.ShellCommand = shellCmd
+		}
+
+		if err := m.CheckMonitors(&config); err != nil {
+			t.Fatalf("checkMonitors(dependencies): unexpected error: %v", err)
+		}
+	}
+
+	if child, _ := config.GetMonitor("Child"); child.IsUnreachable() {
+		t.Errorf("checkMonitors(dependencies): expected child to be reachable after parent recovered")
+	}
+
+	content, err := os.ReadFile(alertLog)
+	if err != nil {
+		t.Fatalf("checkMonitors(dependencies): failed to read alert log: %v", err)
+	}
+
+	expected := []string{"Parent up=false", "Parent up=true"}
+	actual := strings.Split(strings.TrimSpace(string(content)), "\n")
+
+	if !m.EqualSliceString(actual, expected) {
+		t.Errorf("checkMonitors(dependencies): expected=%v actual=%v", expected, actual)
+	}
+}
+
+// TestCheckMonitorsFailingParent tests that a child is unreachable while its parent is failing,
+// even before the parent reaches its alert_after
+func TestCheckMonitorsFailingParent(t *testing.T) {
+	t.Parallel()
+
+	alertLog := filepath.Join(t.TempDir(), "alerts.log")
+	config := m.Config{
+		CheckIntervalStr: "1s",
+		Monitors: []*m.Monitor{
+			{Name: "Child", ShellCommand: "false", AlertDown: []string{"log"}, DependsOn: []string{"Parent"}},
+			{Name: "Parent", ShellCommand: "false", AlertDown: []string{"log"}, AlertAfter: 3},
+		},
+		Alerts: []*m.Alert{{
+			Name:         "log",
+			ShellCommand: "echo '{{.MonitorName}} up={{.IsUp}}' >> " + alertLog,
+		}},
+	}
+
+	if err := config.Init(); err != nil {
+		t.Fatalf("checkMonitors(failing parent): unexpected error reading config: %v", err)
+	}
+
+	if err := m.CheckMonitors(&config); err != nil {
+		t.Fatalf("checkMonitors(failing parent): unexpected error: %v", err)
+	}
+
+	if child, _ := config.GetMonitor("Child"); !child.IsUnreachable() {
+		t.Errorf("checkMonitors(failing parent): expected child to be unreachable while parent is failing")
+	}
+
+	if _, err := os.Stat(alertLog); !os.IsNotExist(err) {
+		t.Errorf("checkMonitors(failing parent): expected no alert to be sent, got %v", err)
+	}
+}
+
+// TestCheckMonitorsSuppressedRecovery tests that a recovery suppressed by maintenance does not stop
+// a down alert of the next outage from being retried
+func TestCheckMonitorsSuppressedRecovery(t *testing.T) {
+	t.Parallel()
+
+	alertLog := filepath.Join(t.TempDir(), "alerts.log")
+	config := m.Config{
+		CheckIntervalStr: "1s",
+		Monitors: []*m.Monitor{
+			{Name: "Child", ShellCommand: "false", AlertDown: []string{"log"}, AlertUp: []string{"log"}, AlertEvery: Ptr(0), DependsOn: []string{"Parent"}},
+			{Name: "Parent", ShellCommand: "true", AlertDown: []string{"log"}, AlertUp: []string{"log"}},
+		},
+		Alerts: []*m.Alert{{
+			Name:         "log",
+			ShellCommand: "echo '{{.MonitorName}} up={{.IsUp}}' >> " + alertLog,
+		}},
+		Maintenance: []*m.Maintenance{{
+			Name:     "upgrade",
+			Monitors: []string{"Child"},
+			StartStr: time.Now().Add(time.Hour).Format(time.RFC3339),
+			EndStr:   time.Now().Add(2 * time.Hour).Format(time.RFC3339),
+		}},
+	}
+
+	if err := config.Init(); err != nil {
+		t.Fatalf("checkMonitors(suppressed recovery): unexpected error reading config: %v", err)
+	}
+
+	child, _ := config.GetMonitor("Child")
+	parent, _ := config.GetMonitor("Parent")
+
+	for _, step := range []struct {
+		childCmd      string
+		parentCmd     string
+		inMaintenance bool
+	}{
+		// Child goes down, then recovers during maintenance
+		{"false", "true", false},
+		{"true", "true", true},
+		// Child goes down again while unreachable, then the parent recovers
+		{"false", "false", false},
+		{"false", "true", false},
+	} {
+		if step.inMaintenance {
+			config.Maintenance[0].Start = time.Now().Add(-time.Minute)
+		} else {
+			config.Maintenance[0].End = config.Maintenance[0].Start
+		}
+
+		child.ShellCommand = step.childCmd
+		parent.ShellCommand = step.parentCmd
+
+		if err := m.CheckMonitors(&config); err != nil {
+			t.Fatalf("checkMonitors(suppressed recovery): unexpected error: %v", err)
+		}
+	}
+
+	content, err := os.ReadFile(alertLog)
+	if err != nil {
+		t.Fatalf("checkMonitors(suppressed recovery): failed to read alert log: %v", err)
+	}
+
+	expected := []string{"Child up=false", "Parent up=false", "Parent up=true", "Child up=false"}
+	actual := strings.Split(strings.TrimSpace(string(content)), "\n")
+
+	if !m.EqualSliceString(actual, expected) {
+		t.Errorf("checkMonitors(suppressed recovery): expected=%v actual=%v", expected, actual)
+	}
+}
+
+// TestCheckMonitorsMaintenance tests that alerts are suppressed during maintenance and sent once it is over
+func TestCheckMonitorsMaintenance(t *testing.T) {
+	t.Parallel()
+
+	alertLog := filepath.Join(t.TempDir(), "alerts.log")
+	config := m.Config{
+		CheckIntervalStr: "1s",
+		Monitors: []*m.Monitor{
+			{Name: "web-1", ShellCommand: "false", AlertDown: []string{"log"}, AlertUp: []string{"log"}, AlertEvery: Ptr(0)},
+		},
+		Alerts: []*m.Alert{{
+			Name:         "log",
+			ShellCommand: "echo '{{.MonitorName}} up={{.IsUp}}' >> " + alertLog,
+		}},
+		Maintenance: []*m.Maintenance{{
+			Name:     "upgrade",
+			Monitors: []string{"web-*"},
+			StartStr: time.Now().Add(-time.Hour).Format(time.RFC3339),
+			EndStr:   time.Now().Add(time.Hour).Format(time.RFC3339),
+		}},
+	}
+
+	if err := config.Init(); err != nil {
+		t.Fatalf("checkMonitors(maintenance): unexpected error reading config: %v", err)
+	}
+
+	// Down and recover during maintenance, then go down again after it ends
+	for _, step := range []struct {
+		shellCmd      string
+		inMaintenance bool
+	}{
+		{"false", true}, {"true", true}, {"false", true}, {"false", false},
+	} {
+		if !step.inMaintenance {
+			config.Maintenance[0].End = time.Now()
+		}
+
+		config.Monitors[0].ShellCommand = step.shellCmd
+
+		if err := m.CheckMonitors(&config); err != nil {
+			t.Fatalf("checkMonitors(maintenance): unexpected error: %v", err)
+		}
+
+		if config.Monitors[0].InMaintenance() != step.inMaintenance {
+			t.Errorf("checkMonitors(maintenance): expected in maintenance=%t", step.inMaintenance)
+		}
+	}
+
+	content, err := os.ReadFile(alertLog)
+	if err != nil {
+		t.Fatalf("checkMonitors(maintenance): failed to read alert log: %v", err)
+	}
+
+	expected := []string{"web-1 up=false"}
+	actual := strings.Split(strings.TrimSpace(string(content)), "\n")
+
+	if !m.EqualSliceString(actual, expected) {
+		t.Errorf("checkMonitors(maintenance): expected=%v actual=%v", expected, actual)
+	}
+}
+
+// TestCheckMonitorsFlapping tests that a flapping notice suppressed by maintenance is sent once
+// maintenance ends, and that the current state is sent when flapping stops
+func TestCheckMonitorsFlapping(t *testing.T) {
+	t.Parallel()
+
+	alertLog := filepath.Join(t.TempDir(), "alerts.log")
+	config := m.Config{
+		CheckIntervalStr: "1s",
+		Monitors: []*m.Monitor{{
+			Name:              "flappy",
+			ShellCommand:      "false",
+			AlertDown:         []string{"log"},
+			AlertUp:           []string{"log"},
+			AlertFlapping:     []string{"log"},
+			AlertEvery:        Ptr(0),
+			FlapThresholdHigh: 30,
+			FlapThresholdLow:  15,
+		}},
+		Alerts: []*m.Alert{{
+			Name:         "log",
+			ShellCommand: "echo '{{.MonitorName}} up={{.IsUp}} flapping={{.IsFlapping}}' >> " + alertLog,
+		}},
+		Maintenance: []*m.Maintenance{{
+			Name:     "upgrade",
+			Monitors: []string{"flappy"},
+			StartStr: time.Now().Add(-time.Hour).Format(time.RFC3339),
+			EndStr:   time.Now().Add(time.Hour).Format(time.RFC3339),
+		}},
+	}
+
+	if err := config.Init(); err != nil {
+		t.Fatalf("checkMonitors(flapping): unexpected error reading config: %v", err)
+	}
+
+	monitor := config.Monitors[0]
+
+	// Start flapping during maintenance
+	for i := 0; i < 12; i++ {
+		monitor.ShellCommand = []string{"false", "true"}[i%2]
+
+		if err := m.CheckMonitors(&config); err != nil {
+			t.Fatalf("checkMonitors(flapping): unexpected error: %v", err)
+		}
+	}
+
+	if !monitor.IsFlapping() {
+		t.Fatalf("checkMonitors(flapping): expected monitor to be flapping, state change=%.1f", monitor.StateChangePercent())
+	}
+
+	// End maintenance and stay down until flapping stops
+	config.Maintenance[0].End = time.Now()
+	monitor.ShellCommand = "false"
+
+	for i := 0; i < 21 && (i == 0 || monitor.IsFlapping()); i++ {
+		if err := m.CheckMonitors(&config); err != nil {
+			t.Fatalf("checkMonitors(flapping): unexpected error: %v", err)
+		}
+	}
+
+	if monitor.IsFlapping() {
+		t.Fatalf("checkMonitors(flapping): expected monitor to stop flapping, state change=%.1f", monitor.StateChangePercent())
+	}
+
+	content, err := os.ReadFile(alertLog)
+	if err != nil {
+		t.Fatalf("checkMonitors(flapping): failed to read alert log: %v", err)
+	}
+
+	expected := []string{"flappy up=false flapping=true", "flappy up=false flapping=false"}
+	actual := strings.Split(strings.TrimSpace(string(content)), "\n")
+
+	if !m.EqualSliceString(actual, expected) {
+		t.Errorf("checkMonitors(flapping): expected=%v actual=%v", expected, actual)
+	}
+}
+
+// TestCheckMonitorsSilenced tests that alerts are not sent for monitors matching an active silence,
+// and that an outage hidden by a silence is alerted once it ends without sending an unmatched recovery
+func TestCheckMonitorsSilenced(t *testing.T) {
+	t.Parallel()
+
+	alertLog := filepath.Join(t.TempDir(), "alerts.log")
+	config := m.Config{
+		CheckIntervalStr: "1s",
+		Monitors: []*m.Monitor{
+			{Name: "silenced-web", ShellCommand: "false", AlertDown: []string{"log"}, AlertUp: []string{"log"}, AlertEvery: Ptr(0)},
+		},
+		Alerts: []*m.Alert{{
+			Name:         "log",
+			ShellCommand: "echo '{{.MonitorName}} up={{.IsUp}}' >> " + alertLog,
+		}},
+	}
+
+	if err := config.Init(); err != nil {
+		t.Fatalf("checkMonitors(silenced): unexpected error reading config: %v", err)
+	}
+
+	monitor := config.Monitors[0]
+
+	for _, step := range []struct {
+		shellCmd   string
+		isSilenced bool
+	}{
+		// Down and recover while silenced, then go down again once the silence expires
+		{"false", true}, {"true", true}, {"false", true}, {"false", false},
+	} {
+		var silence m.Silence
+
+		if step.isSilenced {
+			var err error
+
+			silence, err = m.Silences.Add(m.NewSilence("silenced-*", time.Hour, "me", "testing"))
+			if err != nil {
+				t.Fatalf("checkMonitors(silenced): unexpected error adding silence: %v", err)
+			}
+		}
+
+		monitor.ShellCommand = step.shellCmd
+
+		if err := m.CheckMonitors(&config); err != nil {
+			t.Fatalf("checkMonitors(silenced): unexpected error: %v", err)
+		}
+
+		if step.isSilenced {
+			if err := m.Silences.Expire(silence.ID); err != nil {
+				t.Fatalf("checkMonitors(silenced): unexpected error expiring silence: %v", err)
+			}
+		}
+	}
+
+	content, err := os.ReadFile(alertLog)
+	if err != nil {
+		t.Fatalf("checkMonitors(silenced): failed to read alert log: %v", err)
+	}
+
+	expected := []string{"silenced-web up=false"}
+	actual := strings.Split(strings.TrimSpace(string(content)), "\n")
+
+	if !m.EqualSliceString(actual, expected) {
+		t.Errorf("checkMonitors(silenced): expected=%v actual=%v", expected, actual)
+	}
+}
+
+// TestSendAlertsSilenced tests that alerts sent directly are not sent for monitors matching an active silence
+func TestSendAlertsSilenced(t *testing.T) {
+	t.Parallel()
+
+	alertLog := filepath.Join(t.TempDir(), "alerts.log")
+	config := m.Config{
+		CheckIntervalStr: "1s",
+		Monitors: []*m.Monitor{
+			{Name: "muted-web", ShellCommand: "false", AlertDown: []string{"log"}},
+		},
+		Alerts: []*m.Alert{{
+			Name:         "log",
+			ShellCommand: "echo '{{.MonitorName}}' >> " + alertLog,
+		}},
+	}
+
+	if err := config.Init(); err != nil {
+		t.Fatalf("sendAlerts(silenced): unexpected error reading config: %v", err)
+	}
+
+	silence, err := m.Silences.Add(m.NewSilence("muted-*", time.Hour, "me", "testing"))
+	if err != nil {
+		t.Fatalf("sendAlerts(silenced): unexpected error adding silence: %v", err)
+	}
+
+	notice := &m.AlertNotice{MonitorName: "muted-web", AlertCount: 1}
+	if isSent, err := m.SendAlerts(&config, config.Monitors[0], notice); err != nil || isSent {
+		t.Fatalf("sendAlerts(silenced): expected no alert to be sent, sent=%t err=%v", isSent, err)
+	}
+
+	if _, err = os.Stat(alertLog); !os.IsNotExist(err) {
+		t.Errorf("sendAlerts(silenced): expected no alert to be sent, got %v", err)
+	}
+
+	if err = m.Silences.Expire(silence.ID); err != nil {
+		t.Fatalf("sendAlerts(silenced): unexpected error expiring silence: %v", err)
+	}
+
+	if isSent, err := m.SendAlerts(&config, config.Monitors[0], notice); err != nil || !isSent {
+		t.Fatalf("sendAlerts(silenced): expected alert to be sent, sent=%t err=%v", isSent, err)
+	}
+
+	if _, err = os.Stat(alertLog); err != nil {
+		t.Errorf("sendAlerts(silenced): expected alert to be sent after expiry, got %v", err)
+	}
+}
+
+// TestCheckMonitorsEscalation tests that escalation steps are added to down alerts as the alert count grows
+func TestCheckMonitorsEscalation(t *testing.T) {
+	t.Parallel()
+
+	alertLog := filepath.Join(t.TempDir(), "alerts.log")
+	config := m.Config{
+		CheckIntervalStr: "1s",
+		Monitors: []*m.Monitor{
+			{Name: "escalating", ShellCommand: "false", AlertDown: []string{"chat"}, AlertEvery: Ptr(1), EscalationPolicy: "oncall"},
+		},
+		Alerts: []*m.Alert{
+			{Name: "chat", ShellCommand: "echo 'chat {{.AlertCount}}' >> " + alertLog},
+			{Name: "sms", ShellCommand: "echo 'sms {{.AlertCount}}' >> " + alertLog},
+		},
+		EscalationPolicies: []*m.EscalationPolicy{{
+			Name:  "oncall",
+			Steps: []*m.EscalationStep{{AfterAlerts: 2, Alerts: []string{"sms", "chat"}}},
+		}},
+	}
+
+	if err := config.Init(); err != nil {
+		t.Fatalf("checkMonitors(escalation): unexpected error reading config: %v", err)
+	}
+
+	if err := config.IsValid(); err != nil {
+		t.Fatalf("checkMonitors(escalation): unexpected invalid config: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := m.CheckMonitors(&config); err != nil {
+			t.Fatalf("checkMonitors(escalation): unexpected error: %v", err)
+		}
+	}
+
+	content, err := os.ReadFile(alertLog)
+	if err != nil {
+		t.Fatalf("checkMonitors(escalation): failed to read alert log: %v", err)
+	}
+
+	expected := []string{"chat 1", "chat 2", "sms 2", "chat 3", "sms 3"}
+	actual := strings.Split(strings.TrimSpace(string(content)), "\n")
+
+	if !m.EqualSliceString(actual, expected) {
+		t.Errorf("checkMonitors(escalation): expected=%v actual=%v", expected, actual)
+	}
+}
+
+// TestCheckMonitorsGrouped tests that notices for a grouped alert are sent together as a digest
+func TestCheckMonitorsGrouped(t *testing.T) {
+	t.Parallel()
+
+	alertLog := filepath.Join(t.TempDir(), "alerts.log")
+	config := m.Config{
+		CheckIntervalStr: "1s",
+		Monitors: []*m.Monitor{
+			{Name: "grouped-1", ShellCommand: "false", AlertDown: []string{"digest"}},
+			{Name: "grouped-2", ShellCommand: "false", AlertDown: []string{"digest"}},
+			{Name: "grouped-3", ShellCommand: "false", AlertDown: []string{"digest"}},
+		},
+		Alerts: []*m.Alert{{
+			Name:         "digest",
+			ShellCommand: "echo '{{len .Notices}} {{.MonitorName}}' >> " + alertLog,
+			GroupWaitStr: Ptr("1ns"),
+		}},
+	}
+
+	if err := config.Init(); err != nil {
+		t.Fatalf("checkMonitors(grouped): unexpected error reading config: %v", err)
+	}
+
+	if err := m.CheckMonitors(&config); err != nil {
+		t.Fatalf("checkMonitors(grouped): unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(alertLog)
+	if err != nil {
+		t.Fatalf("checkMonitors(grouped): failed to read alert log: %v", err)
+	}
+
+	expected := []string{"3 grouped-1, grouped-2, grouped-3"}
+	actual := strings.Split(strings.TrimSpace(string(content)), "\n")
+
+	if !m.EqualSliceString(actual, expected) {
+		t.Errorf("checkMonitors(grouped): expected=%v actual=%v", expected, actual)
+	}
+}
+
+// TestAlertRateLimits tests that alerts over a rate limit are suppressed and summarised through the fallback alert
+func TestAlertRateLimits(t *testing.T) {
+	t.Parallel()
+
+	alertLog := filepath.Join(t.TempDir(), "alerts.log")
+	config := m.Config{
+		CheckIntervalStr: "1s",
+		Monitors:         []*m.Monitor{{Name: "limited", ShellCommand: "false", AlertDown: []string{"sms"}}},
+		Alerts: []*m.Alert{
+			{Name: "sms", ShellCommand: "echo sms", RateLimit: &m.RateLimit{Count: 2, PeriodStr: "1h"}},
+			{Name: "chat", ShellCommand: "echo chat"},
+			{Name: "fallback", ShellCommand: "echo '{{.MonitorName}}: {{.LastCheckOutput}}' >> " + alertLog},
+		},
+		RateLimit:      &m.RateLimit{Count: 3, PeriodStr: "1h"},
+		RateLimitAlert: "fallback",
+	}
+
+	if err := config.Init(); err != nil {
+		t.Fatalf("rateLimits: unexpected error reading config: %v", err)
+	}
+
+	if err := config.IsValid(); err != nil {
+		t.Fatalf("rateLimits: unexpected invalid config: %v", err)
+	}
+
+	start := time.Now()
+	sms, _ := config.GetAlert("sms")
+	chat, _ := config.GetAlert("chat")
+	fallback, _ := config.GetAlert("fallback")
+
+	for _, step := range []struct {
+		alert    *m.Alert
+		expected bool
+	}{
+		{sms, true}, {sms, true}, {sms, false}, {chat, true}, {chat, false}, {fallback, true},
+	} {
+		if actual := m.AllowAlert(&config, step.alert, 1, start); actual != step.expected {
+			t.Errorf("rateLimits: AllowAlert(%s), expected=%t actual=%t", step.alert.Name, step.expected, actual)
+		}
+	}
+
+	// Notices are sent as soon as a limit is reached
+	if err := m.SendRateLimitNotices(&config, start.Add(time.Minute)); err != nil {
+		t.Fatalf("rateLimits: unexpected error: %v", err)
+	}
+
+	// Later suppressions are reported at most once per period
+	m.AllowAlert(&config, sms, 1, start.Add(2*time.Minute))
+
+	for _, offset := range []time.Duration{30 * time.Minute, time.Hour + time.Minute} {
+		if err := m.SendRateLimitNotices(&config, start.Add(offset)); err != nil {
+			t.Fatalf("rateLimits: unexpected error: %v", err)
+		}
+	}
+
+	content, err := os.ReadFile(alertLog)
+	if err != nil {
+		t.Fatalf("rateLimits: failed to read alert log: %v", err)
+	}
+
+	expected := []string{
+		"Rate limit for global: rate limit reached, 1 alerts suppressed",
+		"Rate limit for alert sms: rate limit reached, 1 alerts suppressed",
+		"Rate limit for alert sms: rate limit reached, 1 alerts suppressed",
+	}
+	actual := strings.Split(strings.TrimSpace(string(content)), "\n")
+
+	if !m.EqualSliceString(actual, expected) {
+		t.Errorf("rateLimits: expected=%v actual=%v", expected, actual)
+	}
+}
+
+// TestCheckMonitorsRateLimitedRecovery tests that no recovery is sent for an outage whose down
+// alert was dropped by a rate limit
+func TestCheckMonitorsRateLimitedRecovery(t *testing.T) {
+	t.Parallel()
+
+	alertLog := filepath.Join(t.TempDir(), "alerts.log")
+	config := m.Config{
+		CheckIntervalStr: "1s",
+		Monitors: []*m.Monitor{
+			{Name: "a", ShellCommand: "false", AlertDown: []string{"sms"}, AlertUp: []string{"chat"}},
+			{Name: "b", ShellCommand: "false", AlertDown: []string{"sms"}, AlertUp: []string{"chat"}},
+		},
+		Alerts: []*m.Alert{
+			{Name: "sms", ShellCommand: "echo 'down {{.MonitorName}}' >> " + alertLog, RateLimit: &m.RateLimit{Count: 1, PeriodStr: "1h"}},
+			{Name: "chat", ShellCommand: "echo 'up {{.MonitorName}}' >> " + alertLog},
+		},
+	}
+
+	if err := config.Init(); err != nil {
+		t.Fatalf("checkMonitors(rate limited recovery): unexpected error reading config: %v", err)
+	}
+
+	// Both go down, but only the alert for a is sent. Then b recovers
+	for _, shellCmd := range []string{"false", "true"} {
+		config.Monitors[1].ShellCommand = shellCmd
+
+		if err := m.CheckMonitors(&config); err != nil {
+			t.Fatalf("checkMonitors(rate limited recovery): unexpected error: %v", err)
+		}
+	}
+
+	content, err := os.ReadFile(alertLog)
+	if err != nil {
+		t.Fatalf("checkMonitors(rate limited recovery): failed to read alert log: %v", err)
+	}
+
+	expected := []string{"down a"}
+	actual := strings.Split(strings.TrimSpace(string(content)), "\n")
+
+	if !m.EqualSliceString(actual, expected) {
+		t.Errorf("checkMonitors(rate limited recovery): expected=%v actual=%v", expected, actual)
+	}
+}
+
 func TestFirstRunAlerts(t *testing.T) {
 	cases := []struct {
 		config        m.Config
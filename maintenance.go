@@ -0,0 +1,170 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ErrInvalidMaintenance indicates that a maintenance window is not properly configured
+var ErrInvalidMaintenance = errors.New("Invalid maintenance configuration")
+
+// maintenanceTimeFormat is accepted for one-off windows in addition to RFC3339
+const maintenanceTimeFormat = "2006-01-02 15:04"
+
+// Maintenance is a config driven window of time where alerts for matching monitors are suppressed
+type Maintenance struct {
+	Name        string   `hcl:"name,label"`
+	Monitors    []string `hcl:"monitors"`
+	StartStr    string   `hcl:"start,optional"`
+	EndStr      string   `hcl:"end,optional"`
+	Schedule    string   `hcl:"schedule,optional"`
+	DurationStr string   `hcl:"duration,optional"`
+	Timezone    string   `hcl:"timezone,optional"`
+
+	Start    time.Time
+	End      time.Time
+	Duration time.Duration
+	location *time.Location
+	schedule cron.Schedule
+}
+
+// Init parses the time range of the Maintenance window
+func (maintenance *Maintenance) Init() error {
+	var err error
+
+	maintenance.location = time.Local
+
+	if maintenance.Timezone != "" {
+		maintenance.location, err = time.LoadLocation(maintenance.Timezone)
+		if err != nil {
+			return fmt.Errorf("failed to load timezone for maintenance %s: %w", maintenance.Name, err)
+		}
+	}
+
+	if maintenance.StartStr != "" {
+		maintenance.Start, err = maintenance.parseTime(maintenance.StartStr)
+		if err != nil {
+			return err
+		}
+	}
+
+	if maintenance.EndStr != "" {
+		maintenance.End, err = maintenance.parseTime(maintenance.EndStr)
+		if err != nil {
+			return err
+		}
+	}
+
+	if maintenance.Schedule != "" {
+		maintenance.schedule, err = cron.ParseStandard(maintenance.Schedule)
+		if err != nil {
+			return fmt.Errorf("failed to parse schedule for maintenance %s: %w", maintenance.Name, err)
+		}
+	}
+
+	if maintenance.DurationStr != "" {
+		maintenance.Duration, err = time.ParseDuration(maintenance.DurationStr)
+		if err != nil {
+			return fmt.Errorf("failed to parse duration for maintenance %s: %w", maintenance.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (maintenance Maintenance) parseTime(value string) (time.Time, error) {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return parsed, nil
+	}
+
+	parsed, err = time.ParseInLocation(maintenanceTimeFormat, value, maintenance.location)
+	if err != nil {
+		return parsed, fmt.Errorf("failed to parse time %q for maintenance %s: %w", value, maintenance.Name, err)
+	}
+
+	return parsed, nil
+}
+
+// Validate checks that the Maintenance is properly configured and returns errors if not
+func (maintenance Maintenance) Validate() error {
+	isOneOff := maintenance.StartStr != "" || maintenance.EndStr != ""
+	isRecurring := maintenance.Schedule != "" || maintenance.DurationStr != ""
+
+	var err error
+
+	if len(maintenance.Monitors) == 0 {
+		err = errors.Join(err, fmt.Errorf(
+			"%w: maintenance %s has no monitors configured",
+			ErrInvalidMaintenance,
+			maintenance.Name,
+		))
+	}
+
+	for _, pattern := range maintenance.Monitors {
+		if _, matchErr := path.Match(pattern, ""); matchErr != nil {
+			err = errors.Join(err, fmt.Errorf(
+				"%w: maintenance %s has invalid monitor pattern %q",
+				ErrInvalidMaintenance,
+				maintenance.Name,
+				pattern,
+			))
+		}
+	}
+
+	switch {
+	case isOneOff && isRecurring:
+		err = errors.Join(err, fmt.Errorf(
+			"%w: maintenance %s has both start/end and schedule/duration configured",
+			ErrInvalidMaintenance,
+			maintenance.Name,
+		))
+	case isOneOff && (maintenance.Start.IsZero() || !maintenance.End.After(maintenance.Start)):
+		err = errors.Join(err, fmt.Errorf(
+			"%w: maintenance %s must have a start and an end after the start",
+			ErrInvalidMaintenance,
+			maintenance.Name,
+		))
+	case isRecurring && (maintenance.schedule == nil || maintenance.Duration <= 0):
+		err = errors.Join(err, fmt.Errorf(
+			"%w: maintenance %s must have both a schedule and a positive duration",
+			ErrInvalidMaintenance,
+			maintenance.Name,
+		))
+	case !isOneOff && !isRecurring:
+		err = errors.Join(err, fmt.Errorf(
+			"%w: maintenance %s has no start/end or schedule/duration configured",
+			ErrInvalidMaintenance,
+			maintenance.Name,
+		))
+	}
+
+	return err
+}
+
+// IsActive returns true if the given time falls within the Maintenance window
+func (maintenance Maintenance) IsActive(now time.Time) bool {
+	if maintenance.schedule != nil {
+		// The window is active if it was last started within the duration
+		windowStart := maintenance.schedule.Next(now.In(maintenance.location).Add(-maintenance.Duration))
+
+		return !windowStart.After(now)
+	}
+
+	return !now.Before(maintenance.Start) && now.Before(maintenance.End)
+}
+
+// Matches returns true if the monitor name matches any of the configured names or glob patterns
+func (maintenance Maintenance) Matches(monitorName string) bool {
+	for _, pattern := range maintenance.Monitors {
+		if matched, _ := path.Match(pattern, monitorName); matched {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,164 @@
+package main_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	m "git.iamthefij.com/iamthefij/minitor-go/v2"
+)
+
+func TestMaintenanceIsActive(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		maintenance m.Maintenance
+		now         time.Time
+		expected    bool
+		name        string
+	}{
+		{
+			m.Maintenance{StartStr: "2026-01-10T02:00:00Z", EndStr: "2026-01-10T04:00:00Z"},
+			time.Date(2026, 1, 10, 3, 0, 0, 0, time.UTC),
+			true,
+			"One-off during",
+		},
+		{
+			m.Maintenance{StartStr: "2026-01-10T02:00:00Z", EndStr: "2026-01-10T04:00:00Z"},
+			time.Date(2026, 1, 10, 4, 0, 0, 0, time.UTC),
+			false,
+			"One-off at end",
+		},
+		{
+			m.Maintenance{StartStr: "2026-01-10 02:00", EndStr: "2026-01-10 04:00", Timezone: "America/Los_Angeles"},
+			time.Date(2026, 1, 10, 11, 0, 0, 0, time.UTC),
+			true,
+			"One-off in timezone",
+		},
+		{
+			m.Maintenance{Schedule: "0 2 * * SUN", DurationStr: "2h"},
+			time.Date(2026, 1, 11, 3, 59, 0, 0, time.UTC),
+			true,
+			"Recurring during",
+		},
+		{
+			m.Maintenance{Schedule: "0 2 * * SUN", DurationStr: "2h"},
+			time.Date(2026, 1, 11, 4, 1, 0, 0, time.UTC),
+			false,
+			"Recurring after",
+		},
+		{
+			m.Maintenance{Schedule: "0 2 * * SUN", DurationStr: "2h"},
+			time.Date(2026, 1, 12, 3, 0, 0, 0, time.UTC),
+			false,
+			"Recurring wrong day",
+		},
+		{
+			m.Maintenance{Schedule: "0 2 * * *", DurationStr: "1h", Timezone: "America/Los_Angeles"},
+			time.Date(2026, 1, 10, 10, 30, 0, 0, time.UTC),
+			true,
+			"Recurring in timezone",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			c.maintenance.Name = c.name
+			c.maintenance.Monitors = []string{"*"}
+
+			if err := c.maintenance.Init(); err != nil {
+				t.Fatalf("Init(%v), unexpected error: %v", c.name, err)
+			}
+
+			if err := c.maintenance.Validate(); err != nil {
+				t.Fatalf("Validate(%v), unexpected error: %v", c.name, err)
+			}
+
+			if actual := c.maintenance.IsActive(c.now); actual != c.expected {
+				t.Errorf("IsActive(%v), expected=%t actual=%t", c.name, c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMaintenanceMatches(t *testing.T) {
+	t.Parallel()
+
+	maintenance := m.Maintenance{Monitors: []string{"db", "web-*"}}
+
+	for name, expected := range map[string]bool{"db": true, "web-1": true, "web": false, "db-replica": false} {
+		if actual := maintenance.Matches(name); actual != expected {
+			t.Errorf("Matches(%v), expected=%t actual=%t", name, expected, actual)
+		}
+	}
+}
+
+func TestMaintenanceValidate(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		maintenance m.Maintenance
+		expected    error
+		name        string
+	}{
+		{m.Maintenance{Monitors: []string{"*"}, StartStr: "2026-01-10T02:00:00Z", EndStr: "2026-01-10T04:00:00Z"}, nil, "One-off"},
+		{m.Maintenance{Monitors: []string{"*"}, Schedule: "@daily", DurationStr: "1h"}, nil, "Recurring"},
+		{m.Maintenance{StartStr: "2026-01-10T02:00:00Z", EndStr: "2026-01-10T04:00:00Z"}, m.ErrInvalidMaintenance, "No monitors"},
+		{m.Maintenance{Monitors: []string{"["}, Schedule: "@daily", DurationStr: "1h"}, m.ErrInvalidMaintenance, "Invalid pattern"},
+		{m.Maintenance{Monitors: []string{"*"}}, m.ErrInvalidMaintenance, "No time range"},
+		{m.Maintenance{Monitors: []string{"*"}, StartStr: "2026-01-10T04:00:00Z", EndStr: "2026-01-10T02:00:00Z"}, m.ErrInvalidMaintenance, "End before start"},
+		{m.Maintenance{Monitors: []string{"*"}, Schedule: "@daily"}, m.ErrInvalidMaintenance, "Schedule without duration"},
+		{m.Maintenance{Monitors: []string{"*"}, StartStr: "2026-01-10T02:00:00Z", EndStr: "2026-01-10T04:00:00Z", Schedule: "@daily", DurationStr: "1h"}, m.ErrInvalidMaintenance, "Both one-off and recurring"},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := c.maintenance.Init(); err != nil {
+				t.Fatalf("Init(%v), unexpected error: %v", c.name, err)
+			}
+
+			actual := c.maintenance.Validate()
+			hasErr := (actual != nil)
+			expectErr := (c.expected != nil)
+
+			if hasErr != expectErr || !errors.Is(actual, c.expected) {
+				t.Errorf("Validate(%v), expected=%v actual=%v", c.name, c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMaintenanceInit(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		maintenance m.Maintenance
+		name        string
+	}{
+		{m.Maintenance{Timezone: "Mars/Olympus_Mons"}, "Invalid timezone"},
+		{m.Maintenance{StartStr: "tomorrow"}, "Invalid start"},
+		{m.Maintenance{EndStr: "tomorrow"}, "Invalid end"},
+		{m.Maintenance{Schedule: "every day"}, "Invalid schedule"},
+		{m.Maintenance{DurationStr: "forever"}, "Invalid duration"},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := c.maintenance.Init(); err == nil {
+				t.Errorf("Init(%v), expected error", c.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"git.iamthefij.com/iamthefij/slog"
+)
+
+// ErrInvalidMatrix indicates that a matrix alert is not properly configured
+var ErrInvalidMatrix = errors.New("Invalid matrix configuration")
+
+const (
+	// matrixMaxAttempts is how many times a message is sent when the homeserver fails or rate limits
+	matrixMaxAttempts = 3
+	// matrixRetryDelay is how long to wait before retrying when the homeserver does not say
+	matrixRetryDelay = time.Second
+	// matrixTxnIDLength is the number of hex characters of the notice hash used as a transaction id
+	matrixTxnIDLength = 32
+
+	defaultMatrixMsgType  = "m.text"
+	defaultMatrixHTMLBody = "<b>{{.MonitorName}} is {{if .IsUp}}up{{else}}down{{end}}</b><br>" +
+		"Failures: {{.FailureCount}}<br>Last success: {{RFC1123 .LastSuccess}}" +
+		"{{if .LastCheckOutput}}<pre><code>{{.LastCheckOutput}}</code></pre>{{end}}"
+)
+
+// MatrixAlert is a built in alert type that sends a message to a Matrix room
+type MatrixAlert struct {
+	Homeserver  string `hcl:"homeserver"`
+	AccessToken string `hcl:"access_token"`
+	RoomID      string `hcl:"room_id"`
+	MsgType     string `hcl:"msgtype,optional"`
+	Body        string `hcl:"body,optional"`
+	HTMLBody    string `hcl:"html_body,optional"`
+
+	bodyTemplate     *template.Template
+	htmlBodyTemplate *htmltemplate.Template
+}
+
+type matrixMessage struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format"`
+	FormattedBody string `json:"formatted_body"`
+}
+
+// matrixError is the body of an error response from the homeserver
+type matrixError struct {
+	RetryAfterMs int64 `json:"retry_after_ms"`
+}
+
+// Validate checks that the MatrixAlert is properly configured and returns errors if not
+func (matrix MatrixAlert) Validate() error {
+	var err error
+
+	err = errors.Join(err, validateHTTPURL(ErrInvalidMatrix, "homeserver", matrix.Homeserver))
+
+	if matrix.AccessToken == "" {
+		err = errors.Join(err, fmt.Errorf("%w: access_token is required", ErrInvalidMatrix))
+	}
+
+	if !strings.HasPrefix(matrix.RoomID, "!") || !strings.Contains(matrix.RoomID, ":") {
+		err = errors.Join(err, fmt.Errorf("%w: room_id must be a room id like !room:example.com", ErrInvalidMatrix))
+	}
+
+	if msgType := matrix.msgType(); msgType != "m.text" && msgType != "m.notice" {
+		err = errors.Join(err, fmt.Errorf("%w: msgtype must be one of m.text or m.notice", ErrInvalidMatrix))
+	}
+
+	return err
+}
+
+// BuildTemplates compiles the plain and html body templates for the MatrixAlert. Values in the
+// html body are escaped automatically
+func (matrix *MatrixAlert) BuildTemplates(name string, funcs template.FuncMap) error {
+	var err error
+
+	body := matrix.Body
+	if body == "" {
+		body = defaultNoticeMessage
+	}
+
+	matrix.bodyTemplate, err = template.New(name + "-body").Funcs(funcs).Parse(body)
+	if err != nil {
+		return err
+	}
+
+	htmlBody := matrix.HTMLBody
+	if htmlBody == "" {
+		htmlBody = defaultMatrixHTMLBody
+	}
+
+	matrix.htmlBodyTemplate, err = htmltemplate.New(name + "-html_body").Funcs(funcs).Parse(htmlBody)
+
+	return err
+}
+
+// Send renders the message and sends it to the room. Retries reuse the same transaction id so
+// the homeserver does not post the message twice
+func (matrix MatrixAlert) Send(notice AlertNotice) (string, error) {
+	if matrix.bodyTemplate == nil || matrix.htmlBodyTemplate == nil {
+		return "", fmt.Errorf("No templates compiled for matrix: %w", errNoTemplate)
+	}
+
+	body, err := renderTemplate(matrix.bodyTemplate, notice)
+	if err != nil {
+		return "", err
+	}
+
+	var htmlBody strings.Builder
+	if err = matrix.htmlBodyTemplate.Execute(&htmlBody, notice); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", matrix.htmlBodyTemplate.Name(), err)
+	}
+
+	payload, err := json.Marshal(matrixMessage{
+		MsgType:       matrix.msgType(),
+		Body:          body,
+		Format:        "org.matrix.custom.html",
+		FormattedBody: htmlBody.String(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to encode payload: %w", ErrAlertFailed, err)
+	}
+
+	sendURL := fmt.Sprintf(
+		"%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		strings.TrimSuffix(matrix.Homeserver, "/"),
+		url.PathEscape(matrix.RoomID),
+		matrix.txnID(notice),
+	)
+	headers := map[string]string{
+		"Authorization": "Bearer " + matrix.AccessToken,
+		"Content-Type":  "application/json",
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, respBody, err := doHTTPRequest(http.MethodPut, sendURL, headers, payload)
+
+		isRetryable := err != nil ||
+			resp.StatusCode == http.StatusTooManyRequests ||
+			resp.StatusCode >= http.StatusInternalServerError
+		if !isRetryable || attempt >= matrixMaxAttempts {
+			if err != nil {
+				return respBody, err
+			}
+
+			return respBody, checkHTTPStatus(resp)
+		}
+
+		retryAfter := matrixRetryAfter(resp, respBody)
+
+		slog.Warningf("Matrix alert for %s failed to send. Retrying in %s", notice.MonitorName, retryAfter)
+		time.Sleep(retryAfter)
+	}
+}
+
+// txnID derives the transaction id from the outage a notice is about, so that a notice sent again
+// is not posted twice
+func (matrix MatrixAlert) txnID(notice AlertNotice) string {
+	notices := notice.Notices
+	if len(notices) == 0 {
+		notices = []AlertNotice{notice}
+	}
+
+	hash := sha256.New()
+	fmt.Fprintln(hash, matrix.RoomID)
+
+	for _, notice := range notices {
+		failingSince := notice.FailingSince
+		if failingSince.IsZero() {
+			// Notices that are not about an outage, such as rate limit notices, are never sent again
+			failingSince = time.Now()
+		}
+
+		fmt.Fprintln(
+			hash,
+			notice.MonitorName,
+			notice.IsUp,
+			notice.IsFlapping,
+			notice.AlertCount,
+			notice.FailureCount,
+			notice.SuppressedCount,
+			failingSince.UnixNano(),
+		)
+	}
+
+	return "minitor-" + hex.EncodeToString(hash.Sum(nil))[:matrixTxnIDLength]
+}
+
+func (matrix MatrixAlert) msgType() string {
+	if matrix.MsgType == "" {
+		return defaultMatrixMsgType
+	}
+
+	return matrix.MsgType
+}
+
+// matrixRetryAfter returns how long the homeserver asked to wait before retrying, capped at the alert timeout
+func matrixRetryAfter(resp *http.Response, respBody string) time.Duration {
+	var matrixErr matrixError
+
+	if resp == nil || json.Unmarshal([]byte(respBody), &matrixErr) != nil || matrixErr.RetryAfterMs <= 0 {
+		return matrixRetryDelay
+	}
+
+	return min(time.Duration(matrixErr.RetryAfterMs)*time.Millisecond, alertHTTPTimeout)
+}
@@ -0,0 +1,176 @@
+package main_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	m "git.iamthefij.com/iamthefij/minitor-go/v2"
+)
+
+// matrixRequest captures a request received by a test homeserver
+type matrixRequest struct {
+	method string
+	path   string
+	auth   string
+	body   string
+}
+
+// newMatrixServer starts a homeserver that rate limits the first limited requests and records all requests
+func newMatrixServer(t *testing.T, limited int32) (*httptest.Server, chan matrixRequest) {
+	t.Helper()
+
+	count := &atomic.Int32{}
+	requests := make(chan matrixRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		requests <- matrixRequest{method: r.Method, path: r.URL.EscapedPath(), auth: r.Header.Get("Authorization"), body: string(body)}
+
+		if count.Add(1) <= limited {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"errcode": "M_LIMIT_EXCEEDED", "retry_after_ms": 10}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"event_id": "$event"}`))
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, requests
+}
+
+func TestMatrixAlertValidate(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		matrix   m.MatrixAlert
+		expected error
+		name     string
+	}{
+		{m.MatrixAlert{Homeserver: "https://matrix.example.com", AccessToken: "tk", RoomID: "!room:example.com"}, nil, "Valid"},
+		{m.MatrixAlert{Homeserver: "https://matrix.example.com", AccessToken: "tk", RoomID: "!room:example.com", MsgType: "m.notice"}, nil, "Valid notice"},
+		{m.MatrixAlert{AccessToken: "tk", RoomID: "!room:example.com"}, m.ErrInvalidMatrix, "No homeserver"},
+		{m.MatrixAlert{Homeserver: "https://matrix.example.com", RoomID: "!room:example.com"}, m.ErrInvalidMatrix, "No access token"},
+		{m.MatrixAlert{Homeserver: "https://matrix.example.com", AccessToken: "tk", RoomID: "#alerts:example.com"}, m.ErrInvalidMatrix, "Room alias"},
+		{m.MatrixAlert{Homeserver: "https://matrix.example.com", AccessToken: "tk", RoomID: "!room:example.com", MsgType: "m.image"}, m.ErrInvalidMatrix, "Invalid msgtype"},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := c.matrix.Validate()
+			hasErr := (actual != nil)
+			expectErr := (c.expected != nil)
+
+			if hasErr != expectErr || !errors.Is(actual, c.expected) {
+				t.Errorf("Validate(%v), expected=%v actual=%v", c.name, c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMatrixAlertSend(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newMatrixServer(t, 1)
+	alert := m.Alert{Name: "matrix", Matrix: &m.MatrixAlert{
+		Homeserver:  server.URL + "/",
+		AccessToken: "access-token",
+		RoomID:      "!room:example.com",
+		Body:        "{{.MonitorName}}: {{.LastCheckOutput}}",
+	}}
+
+	if err := alert.BuildTemplates(); err != nil {
+		t.Fatalf("BuildTemplates(), unexpected error: %v", err)
+	}
+
+	notice := m.AlertNotice{MonitorName: "web", AlertCount: 1, LastCheckOutput: "<script> & more", FailingSince: time.Now()}
+	if _, err := alert.Send(notice); err != nil {
+		t.Fatalf("Send(), unexpected error: %v", err)
+	}
+
+	limited, sent := <-requests, <-requests
+
+	prefix := "/_matrix/client/v3/rooms/%21room:example.com/send/m.room.message/"
+	if limited.method != http.MethodPut || !strings.HasPrefix(limited.path, prefix) || limited.auth != "Bearer access-token" {
+		t.Errorf("Send(), unexpected request method=%q path=%q auth=%q", limited.method, limited.path, limited.auth)
+	}
+
+	if sent.path != limited.path {
+		t.Errorf("Send(), expected retry to reuse transaction id, got %q then %q", limited.path, sent.path)
+	}
+
+	var message struct {
+		MsgType       string `json:"msgtype"`
+		Body          string `json:"body"`
+		Format        string `json:"format"`
+		FormattedBody string `json:"formatted_body"`
+	}
+
+	if err := json.Unmarshal([]byte(sent.body), &message); err != nil {
+		t.Fatalf("Send(), invalid json body %q: %v", sent.body, err)
+	}
+
+	if message.MsgType != "m.text" || message.Body != "web: <script> & more" || message.Format != "org.matrix.custom.html" {
+		t.Errorf("Send(), unexpected message %q", sent.body)
+	}
+
+	if !strings.Contains(message.FormattedBody, "<b>web is down</b>") ||
+		!strings.Contains(message.FormattedBody, "<pre><code>&lt;script&gt; &amp; more</code></pre>") {
+		t.Errorf("Send(), expected escaped html body, got %q", message.FormattedBody)
+	}
+
+	// The same notice sent again reuses the transaction id
+	if _, err := alert.Send(notice); err != nil {
+		t.Fatalf("Send(), unexpected error: %v", err)
+	}
+
+	if again := <-requests; again.path != sent.path {
+		t.Errorf("Send(), expected the same notice to reuse transaction id, got %q then %q", sent.path, again.path)
+	}
+
+	// A new message gets a new transaction id
+	notice.IsUp = true
+	if _, err := alert.Send(notice); err != nil {
+		t.Fatalf("Send(), unexpected error: %v", err)
+	}
+
+	if next := <-requests; next.path == sent.path {
+		t.Errorf("Send(), expected a new transaction id for a new message, got %q", next.path)
+	}
+}
+
+func TestMatrixAlertSendFailed(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newMatrixServer(t, 5)
+	alert := m.Alert{Name: "matrix", Matrix: &m.MatrixAlert{
+		Homeserver:  server.URL,
+		AccessToken: "access-token",
+		RoomID:      "!room:example.com",
+	}}
+
+	if err := alert.BuildTemplates(); err != nil {
+		t.Fatalf("BuildTemplates(), unexpected error: %v", err)
+	}
+
+	if _, err := alert.Send(m.AlertNotice{MonitorName: "web"}); !errors.Is(err, m.ErrAlertFailed) {
+		t.Errorf("Send(), expected=%v actual=%v", m.ErrAlertFailed, err)
+	}
+
+	if len(requests) != 3 {
+		t.Errorf("Send(), expected 3 attempts, got %d", len(requests))
+	}
+}
@@ -17,10 +17,20 @@ import (
 
 // MinitorMetrics contains all counters and metrics that Minitor will need to access
 type MinitorMetrics struct {
-	alertCount    *prometheus.CounterVec
-	checkCount    *prometheus.CounterVec
-	checkTime     *prometheus.GaugeVec
-	monitorStatus *prometheus.GaugeVec
+	alertCount      *prometheus.CounterVec
+	alertSuppressed *prometheus.CounterVec
+	checkCount      *prometheus.CounterVec
+	checkTime       *prometheus.GaugeVec
+	checkAttempts   *prometheus.GaugeVec
+	checkRetries    *prometheus.CounterVec
+	monitorStatus   *prometheus.GaugeVec
+	flapping        *prometheus.GaugeVec
+	maintenance     *prometheus.GaugeVec
+
+	hostFilesystem *prometheus.GaugeVec
+	hostMemory     *prometheus.GaugeVec
+	hostSwap       *prometheus.GaugeVec
+	hostLoad       *prometheus.GaugeVec
 }
 
 // NewMetrics creates and initializes all metrics
@@ -34,6 +44,13 @@ func NewMetrics() *MinitorMetrics {
 			},
 			[]string{"alert", "monitor"},
 		),
+		alertSuppressed: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "minitor_alert_suppressed_total",
+				Help: "Number of Minitor alerts suppressed by a rate limit",
+			},
+			[]string{"alert", "limit"},
+		),
 		checkCount: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "minitor_check_total",
@@ -48,6 +65,20 @@ func NewMetrics() *MinitorMetrics {
 			},
 			[]string{"monitor", "status"},
 		),
+		checkAttempts: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "minitor_check_attempts",
+				Help: "Number of attempts made during the last check",
+			},
+			[]string{"monitor"},
+		),
+		checkRetries: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "minitor_check_retry_total",
+				Help: "Number of retried check attempts",
+			},
+			[]string{"monitor"},
+		),
 		monitorStatus: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "minitor_monitor_up_count",
@@ -55,13 +86,64 @@ func NewMetrics() *MinitorMetrics {
 			},
 			[]string{"monitor"},
 		),
+		flapping: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "minitor_monitor_flapping",
+				Help: "Whether or not a monitor is currently flapping",
+			},
+			[]string{"monitor"},
+		),
+		maintenance: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "minitor_monitor_maintenance",
+				Help: "Whether or not a monitor is currently in a maintenance window",
+			},
+			[]string{"monitor"},
+		),
+		hostFilesystem: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "minitor_host_filesystem_used_percent",
+				Help: "Percent of filesystem space or inodes used on a mount",
+			},
+			[]string{"monitor", "mount", "resource"},
+		),
+		hostMemory: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "minitor_host_memory_bytes",
+				Help: "Available and total memory of the host",
+			},
+			[]string{"monitor", "type"},
+		),
+		hostSwap: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "minitor_host_swap_used_percent",
+				Help: "Percent of swap used on the host",
+			},
+			[]string{"monitor"},
+		),
+		hostLoad: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "minitor_host_load_average",
+				Help: "Load average of the host",
+			},
+			[]string{"monitor", "period"},
+		),
 	}
 
 	// Register newly created metrics
 	prometheus.MustRegister(metrics.alertCount)
+	prometheus.MustRegister(metrics.alertSuppressed)
 	prometheus.MustRegister(metrics.checkCount)
 	prometheus.MustRegister(metrics.checkTime)
+	prometheus.MustRegister(metrics.checkAttempts)
+	prometheus.MustRegister(metrics.checkRetries)
 	prometheus.MustRegister(metrics.monitorStatus)
+	prometheus.MustRegister(metrics.flapping)
+	prometheus.MustRegister(metrics.maintenance)
+	prometheus.MustRegister(metrics.hostFilesystem)
+	prometheus.MustRegister(metrics.hostMemory)
+	prometheus.MustRegister(metrics.hostSwap)
+	prometheus.MustRegister(metrics.hostLoad)
 
 	return metrics
 }
@@ -76,6 +158,26 @@ func (metrics *MinitorMetrics) SetMonitorStatus(monitor string, isUp bool) {
 	metrics.monitorStatus.With(prometheus.Labels{"monitor": monitor}).Set(val)
 }
 
+// SetMonitorFlapping sets whether or not a Monitor is flapping
+func (metrics *MinitorMetrics) SetMonitorFlapping(monitor string, isFlapping bool) {
+	val := 0.0
+	if isFlapping {
+		val = 1.0
+	}
+
+	metrics.flapping.With(prometheus.Labels{"monitor": monitor}).Set(val)
+}
+
+// SetMonitorMaintenance sets whether or not a Monitor is in a maintenance window
+func (metrics *MinitorMetrics) SetMonitorMaintenance(monitor string, inMaintenance bool) {
+	val := 0.0
+	if inMaintenance {
+		val = 1.0
+	}
+
+	metrics.maintenance.With(prometheus.Labels{"monitor": monitor}).Set(val)
+}
+
 // CountCheck counts the result of a particular Monitor check
 func (metrics *MinitorMetrics) CountCheck(monitor string, isSuccess bool, ms int64, isAlert bool) {
 	status := "failure"
@@ -97,6 +199,19 @@ func (metrics *MinitorMetrics) CountCheck(monitor string, isSuccess bool, ms int
 	).Set(float64(ms))
 }
 
+// CountCheckAttempts records the number of attempts made during a particular Monitor check
+func (metrics *MinitorMetrics) CountCheckAttempts(monitor string, attempts int) {
+	metrics.checkAttempts.With(prometheus.Labels{"monitor": monitor}).Set(float64(attempts))
+	metrics.checkRetries.With(prometheus.Labels{"monitor": monitor}).Add(float64(attempts - 1))
+}
+
+// CountSuppressedAlerts counts alerts that were not sent because of a rate limit
+func (metrics *MinitorMetrics) CountSuppressedAlerts(alert string, limit string, count int) {
+	metrics.alertSuppressed.With(
+		prometheus.Labels{"alert": alert, "limit": limit},
+	).Add(float64(count))
+}
+
 // CountAlert counts an alert
 func (metrics *MinitorMetrics) CountAlert(monitor string, alert string) {
 	metrics.alertCount.With(
@@ -107,6 +222,30 @@ func (metrics *MinitorMetrics) CountAlert(monitor string, alert string) {
 	).Inc()
 }
 
+// SetHostReadings sets the resource values read by a host check
+func (metrics *MinitorMetrics) SetHostReadings(monitor string, readings HostReadings) {
+	for _, filesystem := range readings.Filesystems {
+		metrics.hostFilesystem.With(
+			prometheus.Labels{"monitor": monitor, "mount": filesystem.Mount, "resource": "space"},
+		).Set(filesystem.UsedPercent)
+		metrics.hostFilesystem.With(
+			prometheus.Labels{"monitor": monitor, "mount": filesystem.Mount, "resource": "inodes"},
+		).Set(filesystem.InodesUsedPercent)
+	}
+
+	if readings.hasMemory {
+		metrics.hostMemory.With(prometheus.Labels{"monitor": monitor, "type": "available"}).Set(readings.MemoryAvailableBytes)
+		metrics.hostMemory.With(prometheus.Labels{"monitor": monitor, "type": "total"}).Set(readings.MemoryTotalBytes)
+		metrics.hostSwap.With(prometheus.Labels{"monitor": monitor}).Set(readings.SwapUsedPercent)
+	}
+
+	if readings.hasLoad {
+		metrics.hostLoad.With(prometheus.Labels{"monitor": monitor, "period": "1m"}).Set(readings.Load1)
+		metrics.hostLoad.With(prometheus.Labels{"monitor": monitor, "period": "5m"}).Set(readings.Load5)
+		metrics.hostLoad.With(prometheus.Labels{"monitor": monitor, "period": "15m"}).Set(readings.Load15)
+	}
+}
+
 // ServeMetrics starts an http server with a Prometheus metrics handler
 func ServeMetrics() {
 	http.Handle("/metrics", promhttp.Handler())
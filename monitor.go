@@ -5,11 +5,27 @@ import (
 	"fmt"
 	"math"
 	"os/exec"
+	"strings"
+	"text/template"
 	"time"
 
 	"git.iamthefij.com/iamthefij/slog"
 )
 
+// ErrCheckFailed indicates that a built in check did not pass
+var ErrCheckFailed = errors.New("check failed")
+
+const (
+	// flapHistorySize is the number of recent check results used to detect flapping
+	flapHistorySize = 21
+	// flapWeightMin and flapWeightRange weight recent state changes more heavily than older ones
+	flapWeightMin   = 0.8
+	flapWeightRange = 0.4
+
+	// defaultRetryInterval is how long to wait between attempts of a check when retries are configured
+	defaultRetryInterval = time.Second
+)
+
 // Monitor represents a particular periodic check of a command
 type Monitor struct { //nolint:maligned
 	// Config values
@@ -19,22 +35,71 @@ type Monitor struct { //nolint:maligned
 	Name         string `hcl:"name,label"`
 	AlertCount   int
 	AlertAfter   int      `hcl:"alert_after,optional"`
+	RecoverAfter int      `hcl:"recover_after,optional"`
 	AlertEvery   *int     `hcl:"alert_every,optional"`
 	AlertDown    []string `hcl:"alert_down,optional"`
 	AlertUp      []string `hcl:"alert_up,optional"`
 	Command      []string `hcl:"command,optional"`
 	ShellCommand string   `hcl:"shell_command,optional"`
 
+	Retries          int     `hcl:"retries,optional"`
+	RetryIntervalStr *string `hcl:"retry_interval,optional"`
+	RetryInterval    time.Duration
+
+	DependsOn []string `hcl:"depends_on,optional"`
+
+	Escalation       *Escalation `hcl:"escalation,block"`
+	EscalationPolicy string      `hcl:"escalation_policy,optional"`
+
+	Targets            []string `hcl:"targets,optional"`
+	MinFailures        int      `hcl:"min_failures,optional"`
+	MinFailuresPercent float64  `hcl:"min_failures_percent,optional"`
+
+	AlertFlapping     []string `hcl:"alert_flapping,optional"`
+	FlapThresholdHigh float64  `hcl:"flap_threshold_high,optional"`
+	FlapThresholdLow  float64  `hcl:"flap_threshold_low,optional"`
+
+	SSH     *SSHConfig    `hcl:"ssh,block"`
+	Redis   *RedisCheck   `hcl:"redis,block"`
+	Process *ProcessCheck `hcl:"process,block"`
+	Host    *HostCheck    `hcl:"host,block"`
+	Logfile *LogfileCheck `hcl:"logfile,block"`
+
 	// Other values
 	failureCount      int
+	successCount      int
 	lastCheck         time.Time
 	lastSuccess       time.Time
 	lastOutput        string
 	lastCheckDuration time.Duration
+	lastCheckAttempts int
+	checkHistory      []bool
+	isFlapping        bool
+	isUnreachable     bool
+	inMaintenance     bool
+	downAlertSent     bool
+	flappingAlertSent bool
+	failedTargets     []string
+	failingSince      time.Time
+	escalation        *Escalation
+
+	commandTemplate      []*template.Template
+	shellCommandTemplate *template.Template
+}
+
+// monitorTarget is the context used to render a command for a single target
+type monitorTarget struct {
+	Target string
 }
 
 // Init initializes the Monitor with default values
-func (monitor *Monitor) Init(defaultAlertAfter int, defaultAlertEvery *int, defaultAlertDown []string, defaultAlertUp []string) error {
+func (monitor *Monitor) Init(
+	defaultAlertAfter int,
+	defaultAlertEvery *int,
+	defaultAlertDown []string,
+	defaultAlertUp []string,
+	defaultRecoverAfter int,
+) error {
 	// Parse the check_interval string into a time.Duration
 	if monitor.CheckIntervalStr != nil {
 		var err error
@@ -45,6 +110,18 @@ func (monitor *Monitor) Init(defaultAlertAfter int, defaultAlertEvery *int, defa
 		}
 	}
 
+	// Parse the retry_interval string into a time.Duration
+	monitor.RetryInterval = defaultRetryInterval
+
+	if monitor.RetryIntervalStr != nil {
+		var err error
+
+		monitor.RetryInterval, err = time.ParseDuration(*monitor.RetryIntervalStr)
+		if err != nil {
+			return fmt.Errorf("failed to parse retry_interval duration for monitor %s: %w", monitor.Name, err)
+		}
+	}
+
 	// Set default values for monitor alerts
 	if monitor.AlertAfter == 0 {
 		minAlertAfter := 1
@@ -55,6 +132,15 @@ func (monitor *Monitor) Init(defaultAlertAfter int, defaultAlertEvery *int, defa
 		monitor.AlertEvery = defaultAlertEvery
 	}
 
+	if monitor.RecoverAfter == 0 {
+		minRecoverAfter := 1
+		monitor.RecoverAfter = max(defaultRecoverAfter, minRecoverAfter)
+	}
+
+	if monitor.FlapThresholdHigh > 0 && monitor.FlapThresholdLow == 0 {
+		monitor.FlapThresholdLow = monitor.FlapThresholdHigh / 2 //nolint:mnd
+	}
+
 	if len(monitor.AlertDown) == 0 {
 		monitor.AlertDown = defaultAlertDown
 	}
@@ -63,31 +149,84 @@ func (monitor *Monitor) Init(defaultAlertAfter int, defaultAlertEvery *int, defa
 		monitor.AlertUp = defaultAlertUp
 	}
 
+	if len(monitor.Targets) > 0 {
+		if err := monitor.buildTargetTemplates(); err != nil {
+			return fmt.Errorf("failed to parse command template for monitor %s: %w", monitor.Name, err)
+		}
+	}
+
+	if monitor.Escalation != nil {
+		if err := monitor.Escalation.Init(); err != nil {
+			return fmt.Errorf("failed to initialize escalation for monitor %s: %w", monitor.Name, err)
+		}
+
+		monitor.escalation = monitor.Escalation
+	}
+
+	if monitor.SSH != nil {
+		if err := monitor.SSH.Init(); err != nil {
+			return fmt.Errorf("failed to initialize ssh for monitor %s: %w", monitor.Name, err)
+		}
+	}
+
+	if monitor.Redis != nil {
+		if err := monitor.Redis.Init(); err != nil {
+			return fmt.Errorf("failed to initialize redis check for monitor %s: %w", monitor.Name, err)
+		}
+	}
+
+	if monitor.Process != nil {
+		if err := monitor.Process.Init(); err != nil {
+			return fmt.Errorf("failed to initialize process check for monitor %s: %w", monitor.Name, err)
+		}
+	}
+
+	if monitor.Host != nil {
+		if err := monitor.Host.Init(); err != nil {
+			return fmt.Errorf("failed to initialize host check for monitor %s: %w", monitor.Name, err)
+		}
+	}
+
+	if monitor.Logfile != nil {
+		if err := monitor.Logfile.Init(); err != nil {
+			return fmt.Errorf("failed to initialize logfile check for monitor %s: %w", monitor.Name, err)
+		}
+	}
+
 	return nil
 }
 
 // Validate checks that the Monitor is properly configured and returns errors if not
 func (monitor Monitor) Validate() error {
-	hasCommand := len(monitor.Command) > 0
-	hasShellCommand := monitor.ShellCommand != ""
+	checkCount := monitor.checkCount()
 	hasValidAlertAfter := monitor.AlertAfter > 0
+	hasValidRecoverAfter := monitor.RecoverAfter > 0
 	hasAlertDown := len(monitor.AlertDown) > 0
 
 	var err error
 
-	hasAtLeastOneCommand := hasCommand || hasShellCommand
-	if !hasAtLeastOneCommand {
+	hasAtLeastOneCheck := checkCount > 0
+	if !hasAtLeastOneCheck {
 		err = errors.Join(err, fmt.Errorf(
-			"%w: monitor %s has no command or shell_command configured",
+			"%w: monitor %s has no command, shell_command or check block configured",
 			ErrInvalidMonitor,
 			monitor.Name,
 		))
 	}
 
-	hasAtMostOneCommand := !(hasCommand && hasShellCommand)
-	if !hasAtMostOneCommand {
+	hasAtMostOneCheck := checkCount <= 1
+	if !hasAtMostOneCheck {
 		err = errors.Join(err, fmt.Errorf(
-			"%w: monitor %s has both command and shell_command configured",
+			"%w: monitor %s has more than one of command, shell_command or check block configured",
+			ErrInvalidMonitor,
+			monitor.Name,
+		))
+	}
+
+	hasRemoteCommand := monitor.SSH == nil || len(monitor.Command) > 0 || monitor.ShellCommand != ""
+	if !hasRemoteCommand {
+		err = errors.Join(err, fmt.Errorf(
+			"%w: monitor %s has ssh configured, but no command or shell_command to run",
 			ErrInvalidMonitor,
 			monitor.Name,
 		))
@@ -102,9 +241,71 @@ func (monitor Monitor) Validate() error {
 		))
 	}
 
-	if !hasAlertDown {
+	if monitor.Retries < 0 {
+		err = errors.Join(err, fmt.Errorf(
+			"%w: monitor %s has invalid retries value %d. Must not be negative",
+			ErrInvalidMonitor,
+			monitor.Name,
+			monitor.Retries,
+		))
+	}
+
+	if monitor.RetryInterval < 0 {
+		err = errors.Join(err, fmt.Errorf(
+			"%w: monitor %s has invalid retry_interval value %s. Must not be negative",
+			ErrInvalidMonitor,
+			monitor.Name,
+			monitor.RetryInterval,
+		))
+	}
+
+	if !hasValidRecoverAfter {
 		err = errors.Join(err, fmt.Errorf(
-			"%w: monitor %s has no alert_down configured. Configure one here or add a default_alert_down",
+			"%w: monitor %s has invalid recover_after value %d. Must be greater than 0",
+			ErrInvalidMonitor,
+			monitor.Name,
+			monitor.RecoverAfter,
+		))
+	}
+
+	hasValidFlapThresholds := monitor.FlapThresholdHigh == 0 ||
+		(monitor.FlapThresholdLow > 0 && monitor.FlapThresholdLow <= monitor.FlapThresholdHigh && monitor.FlapThresholdHigh <= 100)
+	if !hasValidFlapThresholds {
+		err = errors.Join(err, fmt.Errorf(
+			"%w: monitor %s has invalid flap thresholds low=%v high=%v. Must be 0 < low <= high <= 100",
+			ErrInvalidMonitor,
+			monitor.Name,
+			monitor.FlapThresholdLow,
+			monitor.FlapThresholdHigh,
+		))
+	}
+
+	err = errors.Join(err, monitor.validateTargets())
+
+	if monitor.Escalation != nil && monitor.EscalationPolicy != "" {
+		err = errors.Join(err, fmt.Errorf(
+			"%w: monitor %s has both an escalation block and escalation_policy configured",
+			ErrInvalidMonitor,
+			monitor.Name,
+		))
+	}
+
+	if monitor.Escalation != nil {
+		if escalationErr := monitor.Escalation.Validate(); escalationErr != nil {
+			err = errors.Join(err, fmt.Errorf("%w: monitor %s. %w", ErrInvalidMonitor, monitor.Name, escalationErr))
+		}
+	}
+
+	// Escalation policies are resolved when the config is initialized
+	escalation := monitor.escalation
+	if escalation == nil {
+		escalation = monitor.Escalation
+	}
+
+	if !hasAlertDown && (escalation == nil || !escalation.IsReachedOnFirstAlert()) {
+		err = errors.Join(err, fmt.Errorf(
+			"%w: monitor %s has no alert_down configured or escalation step with after_alerts of 1. "+
+				"Configure one here or add a default_alert_down",
 			ErrInvalidMonitor,
 			monitor.Name,
 		))
@@ -113,6 +314,180 @@ func (monitor Monitor) Validate() error {
 	return err
 }
 
+// validateTargets checks that quorum settings are only used with targets and are within range
+func (monitor Monitor) validateTargets() error {
+	hasQuorum := monitor.MinFailures != 0 || monitor.MinFailuresPercent != 0
+
+	if len(monitor.Targets) == 0 {
+		if hasQuorum {
+			return fmt.Errorf(
+				"%w: monitor %s has min_failures or min_failures_percent configured, but no targets",
+				ErrInvalidMonitor,
+				monitor.Name,
+			)
+		}
+
+		return nil
+	}
+
+	var err error
+
+	if len(monitor.Command) == 0 && monitor.ShellCommand == "" {
+		err = errors.Join(err, fmt.Errorf(
+			"%w: monitor %s has targets configured, but no command or shell_command to run for them",
+			ErrInvalidMonitor,
+			monitor.Name,
+		))
+	}
+
+	switch {
+	case monitor.MinFailures != 0 && monitor.MinFailuresPercent != 0:
+		err = errors.Join(err, fmt.Errorf(
+			"%w: monitor %s has both min_failures and min_failures_percent configured",
+			ErrInvalidMonitor,
+			monitor.Name,
+		))
+	case !hasQuorum:
+		err = errors.Join(err, fmt.Errorf(
+			"%w: monitor %s has targets configured, but no min_failures or min_failures_percent",
+			ErrInvalidMonitor,
+			monitor.Name,
+		))
+	case monitor.MinFailures < 0 || monitor.MinFailures > len(monitor.Targets):
+		err = errors.Join(err, fmt.Errorf(
+			"%w: monitor %s has invalid min_failures value %d. Must be between 1 and the number of targets",
+			ErrInvalidMonitor,
+			monitor.Name,
+			monitor.MinFailures,
+		))
+	case monitor.MinFailuresPercent < 0 || monitor.MinFailuresPercent > 100:
+		err = errors.Join(err, fmt.Errorf(
+			"%w: monitor %s has invalid min_failures_percent value %v. Must be 0 < min_failures_percent <= 100",
+			ErrInvalidMonitor,
+			monitor.Name,
+			monitor.MinFailuresPercent,
+		))
+	}
+
+	return err
+}
+
+// buildTargetTemplates compiles the command templates that targets are substituted into
+func (monitor *Monitor) buildTargetTemplates() error {
+	if monitor.ShellCommand != "" {
+		var err error
+
+		monitor.shellCommandTemplate, err = template.New(monitor.Name + "-shell_command").Parse(monitor.ShellCommand)
+		if err != nil {
+			return err
+		}
+	}
+
+	monitor.commandTemplate = []*template.Template{}
+
+	for i, arg := range monitor.Command {
+		tmpl, err := template.New(fmt.Sprintf("%s-command-%d", monitor.Name, i)).Parse(arg)
+		if err != nil {
+			return err
+		}
+
+		monitor.commandTemplate = append(monitor.commandTemplate, tmpl)
+	}
+
+	return nil
+}
+
+// renderTargetCommand returns the command and shell command with the target substituted in
+func (monitor Monitor) renderTargetCommand(target string) ([]string, string, error) {
+	context := monitorTarget{Target: target}
+	command := []string{}
+
+	for _, tmpl := range monitor.commandTemplate {
+		var arg strings.Builder
+		if err := tmpl.Execute(&arg, context); err != nil {
+			return nil, "", err
+		}
+
+		command = append(command, arg.String())
+	}
+
+	var shellCommand strings.Builder
+
+	if monitor.shellCommandTemplate != nil {
+		if err := monitor.shellCommandTemplate.Execute(&shellCommand, context); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return command, shellCommand.String(), nil
+}
+
+// requiredFailures returns how many targets must fail for the Monitor to fail
+func (monitor Monitor) requiredFailures() int {
+	if monitor.MinFailuresPercent > 0 {
+		return max(1, int(math.Ceil(monitor.MinFailuresPercent*float64(len(monitor.Targets))/100))) //nolint:mnd
+	}
+
+	return monitor.MinFailures
+}
+
+// runTargets runs the command for each target and fails if the quorum of failures is reached
+func (monitor *Monitor) runTargets() (string, error) {
+	var output strings.Builder
+
+	monitor.failedTargets = []string{}
+
+	for _, target := range monitor.Targets {
+		command, shellCommand, err := monitor.renderTargetCommand(target)
+
+		var targetOutput string
+		if err == nil {
+			targetOutput, err = monitor.runCommand(command, shellCommand)
+		}
+
+		fmt.Fprintf(&output, "[%s] %s\n", target, strings.TrimSpace(targetOutput))
+
+		if err != nil {
+			slog.Debugf("Monitor %s target %s failed: %v", monitor.Name, target, err)
+			monitor.failedTargets = append(monitor.failedTargets, target)
+		}
+	}
+
+	required := monitor.requiredFailures()
+	if len(monitor.failedTargets) >= required {
+		return output.String(), fmt.Errorf(
+			"%w: %d of %d targets failed, quorum is %d: %s",
+			ErrCheckFailed,
+			len(monitor.failedTargets),
+			len(monitor.Targets),
+			required,
+			strings.Join(monitor.failedTargets, ", "),
+		)
+	}
+
+	return output.String(), nil
+}
+
+// checkCount returns the number of mutually exclusive checks configured for the Monitor
+func (monitor Monitor) checkCount() int {
+	count := 0
+
+	for _, isConfigured := range []bool{
+		len(monitor.Command) > 0,
+		monitor.ShellCommand != "",
+		monitor.Redis != nil,
+		monitor.Process != nil,
+		monitor.Host != nil,
+		monitor.Logfile != nil,
+	} {
+		if isConfigured {
+			count++
+		}
+	}
+
+	return count
+}
+
 func (monitor Monitor) LastOutput() string {
 	return monitor.lastOutput
 }
@@ -128,21 +503,52 @@ func (monitor Monitor) ShouldCheck() bool {
 	return sinceLastCheck >= monitor.CheckInterval
 }
 
-// Check will run the command configured by the Monitor and return a status and a possible AlertNotice
-func (monitor *Monitor) Check() (bool, *AlertNotice) {
+// runCheck executes the configured check and returns its output and result
+func (monitor *Monitor) runCheck() (string, error) {
+	switch {
+	case len(monitor.Targets) > 0:
+		return monitor.runTargets()
+	case monitor.Redis != nil:
+		return monitor.Redis.Check()
+	case monitor.Process != nil:
+		return monitor.Process.Check()
+	case monitor.Host != nil:
+		return monitor.Host.Check()
+	case monitor.Logfile != nil:
+		return monitor.Logfile.Check()
+	default:
+		return monitor.runCommand(monitor.Command, monitor.ShellCommand)
+	}
+}
+
+// runCommand executes the command or shell command, either locally or over ssh
+func (monitor Monitor) runCommand(command []string, shellCommand string) (string, error) {
 	var cmd *exec.Cmd
-	if len(monitor.Command) > 0 {
-		cmd = exec.Command(monitor.Command[0], monitor.Command[1:]...)
-	} else if monitor.ShellCommand != "" {
-		cmd = ShellCommand(monitor.ShellCommand)
-	} else {
+
+	switch {
+	case monitor.SSH != nil && len(command) > 0:
+		return monitor.SSH.Run(ShellQuote(command))
+	case monitor.SSH != nil && shellCommand != "":
+		return monitor.SSH.Run(strings.TrimSpace(shellCommand))
+	case len(command) > 0:
+		cmd = exec.Command(command[0], command[1:]...)
+	case shellCommand != "":
+		cmd = ShellCommand(shellCommand)
+	default:
 		slog.Fatalf("Monitor %s has no command configured", monitor.Name)
 	}
 
-	checkStartTime := time.Now()
 	output, err := cmd.CombinedOutput()
+
+	return string(output), err
+}
+
+// Check will run the check configured by the Monitor and return a status and a possible AlertNotice
+func (monitor *Monitor) Check() (bool, *AlertNotice) {
+	checkStartTime := time.Now()
+	output, err := monitor.runCheckWithRetries()
 	monitor.lastCheck = time.Now()
-	monitor.lastOutput = string(output)
+	monitor.lastOutput = output
 	monitor.lastCheckDuration = monitor.lastCheck.Sub(checkStartTime)
 
 	var alertNotice *AlertNotice
@@ -154,6 +560,8 @@ func (monitor *Monitor) Check() (bool, *AlertNotice) {
 		alertNotice = monitor.Failure()
 	}
 
+	alertNotice = monitor.detectFlapping(isSuccess, alertNotice)
+
 	slog.Debugf("Command output: %s", monitor.lastOutput)
 	slog.OnErrWarnf(err, "Command result: %v", err)
 
@@ -167,6 +575,113 @@ func (monitor *Monitor) Check() (bool, *AlertNotice) {
 	return isSuccess, alertNotice
 }
 
+// runCheckWithRetries runs the check, retrying failures up to Retries times. When retried, the
+// output of every attempt is kept
+func (monitor *Monitor) runCheckWithRetries() (string, error) {
+	output, err := monitor.runCheck()
+	monitor.lastCheckAttempts = 1
+
+	if err == nil || monitor.Retries == 0 {
+		return output, err
+	}
+
+	var allOutput strings.Builder
+
+	fmt.Fprintf(&allOutput, "Attempt 1 of %d: %v\n%s", monitor.Retries+1, err, output)
+
+	for attempt := 2; attempt <= monitor.Retries+1 && err != nil; attempt++ {
+		slog.Debugf("Retrying monitor %s in %s after failed attempt: %v", monitor.Name, monitor.RetryInterval, err)
+		time.Sleep(monitor.RetryInterval)
+
+		output, err = monitor.runCheck()
+		monitor.lastCheckAttempts = attempt
+
+		result := "success"
+		if err != nil {
+			result = err.Error()
+		}
+
+		fmt.Fprintf(&allOutput, "\nAttempt %d of %d: %s\n%s", attempt, monitor.Retries+1, result, output)
+	}
+
+	return allOutput.String(), err
+}
+
+// detectFlapping records the check result and returns the notice that should be sent.
+// While flapping, regular notices are suppressed and a single flapping notice is sent
+// when flapping starts. When flapping stops, a notice is sent if the state differs from the
+// last alert sent.
+func (monitor *Monitor) detectFlapping(isSuccess bool, notice *AlertNotice) *AlertNotice {
+	if monitor.FlapThresholdHigh <= 0 {
+		return notice
+	}
+
+	monitor.checkHistory = append(monitor.checkHistory, isSuccess)
+	if len(monitor.checkHistory) > flapHistorySize {
+		monitor.checkHistory = monitor.checkHistory[1:]
+	}
+
+	stateChange := monitor.StateChangePercent()
+
+	switch {
+	case !monitor.isFlapping && stateChange >= monitor.FlapThresholdHigh:
+		slog.Infof("%s started flapping with %.1f%% state change", monitor.Name, stateChange)
+
+		monitor.isFlapping = true
+
+		return monitor.createFlappingNotice()
+	case monitor.isFlapping && stateChange < monitor.FlapThresholdLow:
+		slog.Infof("%s stopped flapping with %.1f%% state change", monitor.Name, stateChange)
+
+		monitor.isFlapping = false
+		monitor.flappingAlertSent = false
+
+		// Alerts were suppressed while flapping, so catch up to a down or recovered state
+		if notice == nil && monitor.IsUp() == monitor.downAlertSent {
+			notice = monitor.createAlertNotice(monitor.IsUp())
+		}
+	}
+
+	if monitor.isFlapping {
+		if notice != nil {
+			slog.Debugf("%s is flapping. Suppressing alert notice", monitor.Name)
+		}
+
+		return nil
+	}
+
+	return notice
+}
+
+// StateChangePercent returns the weighted percent of recent checks that changed state
+func (monitor Monitor) StateChangePercent() float64 {
+	weightedChanges := 0.0
+
+	for i := 1; i < len(monitor.checkHistory); i++ {
+		if monitor.checkHistory[i] != monitor.checkHistory[i-1] {
+			// Weight oldest changes at 0.8 up to 1.2 for the newest
+			weightedChanges += flapWeightMin + flapWeightRange*float64(i-1)/float64(flapHistorySize-2)
+		}
+	}
+
+	return weightedChanges / float64(flapHistorySize-1) * 100 //nolint:mnd
+}
+
+// IsUnreachable returns true if a monitor this one depends on was down when it was last checked
+func (monitor Monitor) IsUnreachable() bool {
+	return monitor.isUnreachable
+}
+
+// InMaintenance returns true if a maintenance window for the monitor was active when it was last checked
+func (monitor Monitor) InMaintenance() bool {
+	return monitor.inMaintenance
+}
+
+// IsFlapping returns true if the monitor is changing state too often
+func (monitor Monitor) IsFlapping() bool {
+	return monitor.isFlapping
+}
+
 // GetAlertNames gives a list of alert names for a given monitor status
 func (monitor Monitor) GetAlertNames(up bool) []string {
 	if up {
@@ -176,6 +691,20 @@ func (monitor Monitor) GetAlertNames(up bool) []string {
 	return monitor.AlertDown
 }
 
+// GetDownAlertNames returns the down alerts along with those of any escalation steps reached after the given number of alerts
+func (monitor Monitor) GetDownAlertNames(alertCount int) []string {
+	if monitor.escalation == nil {
+		return monitor.AlertDown
+	}
+
+	return mergeAlertNames(monitor.AlertDown, monitor.escalation.AlertNames(alertCount, time.Since(monitor.failingSince)))
+}
+
+// IsFailing returns true if the last check of the monitor failed, even if it has not alerted yet
+func (monitor Monitor) IsFailing() bool {
+	return monitor.failureCount > 0
+}
+
 // IsUp returns the status of the current monitor
 func (monitor Monitor) IsUp() bool {
 	return monitor.AlertCount == 0
@@ -186,9 +715,31 @@ func (monitor Monitor) LastCheckMilliseconds() int64 {
 	return monitor.lastCheckDuration.Milliseconds()
 }
 
+// LastCheckAttempts returns the number of attempts made during the last check
+func (monitor Monitor) LastCheckAttempts() int {
+	return monitor.lastCheckAttempts
+}
+
 func (monitor *Monitor) Success() (notice *AlertNotice) {
+	monitor.successCount++
+
 	if !monitor.IsUp() {
-		// Alert that we have recovered
+		// Remain down until we have enough consecutive successes to be considered recovered
+		if monitor.successCount < monitor.RecoverAfter {
+			slog.Debugf(
+				"%s succeeded but did not hit minimum successes to recover. "+
+					"Count: %v recover after: %v",
+				monitor.Name,
+				monitor.successCount,
+				monitor.RecoverAfter,
+			)
+
+			monitor.lastSuccess = time.Now()
+
+			return
+		}
+
+		// Alert that we have recovered, including the success before this one
 		notice = monitor.createAlertNotice(true)
 	}
 
@@ -200,7 +751,12 @@ func (monitor *Monitor) Success() (notice *AlertNotice) {
 }
 
 func (monitor *Monitor) Failure() (notice *AlertNotice) {
+	monitor.successCount = 0
 	monitor.failureCount++
+
+	if monitor.failureCount == 1 {
+		monitor.failingSince = time.Now()
+	}
 	// If we haven't hit the minimum failures, we can exit
 	if monitor.failureCount < monitor.AlertAfter {
 		slog.Debugf(
@@ -245,14 +801,25 @@ func (monitor *Monitor) Failure() (notice *AlertNotice) {
 	return notice
 }
 
+// createFlappingNotice creates a notice for the alert_flapping alerts with the current state
+func (monitor Monitor) createFlappingNotice() *AlertNotice {
+	notice := monitor.createAlertNotice(monitor.IsUp())
+	notice.IsFlapping = true
+
+	return notice
+}
+
 func (monitor Monitor) createAlertNotice(isUp bool) *AlertNotice {
 	// TODO: Maybe add something about recovery status here
 	return &AlertNotice{
 		MonitorName:     monitor.Name,
 		AlertCount:      monitor.AlertCount,
 		FailureCount:    monitor.failureCount,
+		SuccessCount:    monitor.successCount,
+		FailedTargets:   monitor.failedTargets,
 		LastCheckOutput: monitor.lastOutput,
 		LastSuccess:     monitor.lastSuccess,
+		FailingSince:    monitor.failingSince,
 		IsUp:            isUp,
 	}
 }
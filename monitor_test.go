@@ -2,7 +2,9 @@ package main_test
 
 import (
 	"errors"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -17,11 +19,29 @@ func TestMonitorValidate(t *testing.T) {
 		expected error
 		name     string
 	}{
-		{m.Monitor{AlertAfter: 1, Command: []string{"echo", "test"}, AlertDown: []string{"log"}}, nil, "Command only"},
-		{m.Monitor{AlertAfter: 1, ShellCommand: "echo test", AlertDown: []string{"log"}}, nil, "CommandShell only"},
-		{m.Monitor{AlertAfter: 1, Command: []string{"echo", "test"}}, m.ErrInvalidMonitor, "No AlertDown"},
-		{m.Monitor{AlertAfter: 1, AlertDown: []string{"log"}}, m.ErrInvalidMonitor, "No commands"},
-		{m.Monitor{AlertAfter: -1, Command: []string{"echo", "test"}, AlertDown: []string{"log"}}, m.ErrInvalidMonitor, "Invalid alert threshold, -1"},
+		{m.Monitor{AlertAfter: 1, RecoverAfter: 1, Command: []string{"echo", "test"}, AlertDown: []string{"log"}}, nil, "Command only"},
+		{m.Monitor{AlertAfter: 1, RecoverAfter: 1, ShellCommand: "echo test", AlertDown: []string{"log"}}, nil, "CommandShell only"},
+		{m.Monitor{AlertAfter: 1, RecoverAfter: 1, Command: []string{"echo", "test"}}, m.ErrInvalidMonitor, "No AlertDown"},
+		{m.Monitor{AlertAfter: 1, RecoverAfter: 1, AlertDown: []string{"log"}}, m.ErrInvalidMonitor, "No commands"},
+		{m.Monitor{AlertAfter: -1, RecoverAfter: 1, Command: []string{"echo", "test"}, AlertDown: []string{"log"}}, m.ErrInvalidMonitor, "Invalid alert threshold, -1"},
+		{m.Monitor{AlertAfter: 1, RecoverAfter: 0, Command: []string{"echo", "test"}, AlertDown: []string{"log"}}, m.ErrInvalidMonitor, "Invalid recover threshold, 0"},
+		{m.Monitor{AlertAfter: 1, RecoverAfter: -1, Command: []string{"echo", "test"}, AlertDown: []string{"log"}}, m.ErrInvalidMonitor, "Invalid recover threshold, -1"},
+		{m.Monitor{AlertAfter: 1, RecoverAfter: 1, Retries: -1, Command: []string{"echo", "test"}, AlertDown: []string{"log"}}, m.ErrInvalidMonitor, "Invalid retries, -1"},
+		{m.Monitor{AlertAfter: 1, RecoverAfter: 1, FlapThresholdHigh: 20, FlapThresholdLow: 30, Command: []string{"echo", "test"}, AlertDown: []string{"log"}}, m.ErrInvalidMonitor, "Invalid flap thresholds"},
+		{m.Monitor{AlertAfter: 1, RecoverAfter: 1, Redis: &m.RedisCheck{Address: "localhost:6379"}, AlertDown: []string{"log"}}, nil, "Redis only"},
+		{m.Monitor{AlertAfter: 1, RecoverAfter: 1, SSH: &m.SSHConfig{Host: "localhost"}, AlertDown: []string{"log"}}, m.ErrInvalidMonitor, "SSH without command"},
+		{m.Monitor{AlertAfter: 1, RecoverAfter: 1, Process: &m.ProcessCheck{Name: "minitor"}, AlertDown: []string{"log"}}, nil, "Process only"},
+		{m.Monitor{AlertAfter: 1, RecoverAfter: 1, Command: []string{"echo", "test"}, Redis: &m.RedisCheck{Address: "localhost:6379"}, AlertDown: []string{"log"}}, m.ErrInvalidMonitor, "Command and redis"},
+		{m.Monitor{AlertAfter: 1, RecoverAfter: 1, Targets: []string{"a", "b"}, MinFailures: 2, ShellCommand: "ping {{.Target}}", AlertDown: []string{"log"}}, nil, "Targets with min_failures"},
+		{m.Monitor{AlertAfter: 1, RecoverAfter: 1, Targets: []string{"a", "b"}, MinFailuresPercent: 50, ShellCommand: "ping {{.Target}}", AlertDown: []string{"log"}}, nil, "Targets with min_failures_percent"},
+		{m.Monitor{AlertAfter: 1, RecoverAfter: 1, Targets: []string{"a", "b"}, ShellCommand: "ping {{.Target}}", AlertDown: []string{"log"}}, m.ErrInvalidMonitor, "Targets without quorum"},
+		{m.Monitor{AlertAfter: 1, RecoverAfter: 1, Targets: []string{"a", "b"}, MinFailures: 3, ShellCommand: "ping {{.Target}}", AlertDown: []string{"log"}}, m.ErrInvalidMonitor, "Targets with too many min_failures"},
+		{m.Monitor{AlertAfter: 1, RecoverAfter: 1, Targets: []string{"a", "b"}, MinFailures: 1, MinFailuresPercent: 50, ShellCommand: "ping {{.Target}}", AlertDown: []string{"log"}}, m.ErrInvalidMonitor, "Targets with both quorums"},
+		{m.Monitor{AlertAfter: 1, RecoverAfter: 1, Targets: []string{"a", "b"}, MinFailuresPercent: 150, ShellCommand: "ping {{.Target}}", AlertDown: []string{"log"}}, m.ErrInvalidMonitor, "Targets with invalid percent"},
+		{m.Monitor{AlertAfter: 1, RecoverAfter: 1, Targets: []string{"a"}, MinFailures: 1, Host: &m.HostCheck{}, AlertDown: []string{"log"}}, m.ErrInvalidMonitor, "Targets with built in check"},
+		{m.Monitor{AlertAfter: 1, RecoverAfter: 1, MinFailures: 1, ShellCommand: "echo test", AlertDown: []string{"log"}}, m.ErrInvalidMonitor, "Quorum without targets"},
+		{m.Monitor{AlertAfter: 1, RecoverAfter: 1, ShellCommand: "echo test", Escalation: &m.Escalation{Steps: []*m.EscalationStep{{AfterAlerts: 1, Alerts: []string{"log"}}}}}, nil, "Escalation without AlertDown"},
+		{m.Monitor{AlertAfter: 1, RecoverAfter: 1, ShellCommand: "echo test", Escalation: &m.Escalation{Steps: []*m.EscalationStep{{AfterAlerts: 2, Alerts: []string{"log"}}}}}, m.ErrInvalidMonitor, "Escalation without AlertDown or first step"},
 	}
 
 	for _, c := range cases {
@@ -155,6 +175,103 @@ func TestMonitorAlertCount(t *testing.T) {
 	}
 }
 
+// TestMonitorRecoverAfter tests that a down monitor will not recover until
+// hitting the threshold of consecutive successes provided by RecoverAfter
+func TestMonitorRecoverAfter(t *testing.T) {
+	type step struct {
+		checkSuccess bool
+		expectNotice bool
+		expectUp     bool
+	}
+
+	monitor := m.Monitor{AlertAfter: 1, AlertEvery: Ptr(0), RecoverAfter: 2}
+
+	for i, s := range []step{
+		{false, true, false},
+		{true, false, false},
+		{false, false, false},
+		{true, false, false},
+		{true, true, true},
+		{true, false, true},
+	} {
+		stepStart := time.Now()
+
+		var notice *m.AlertNotice
+		if s.checkSuccess {
+			notice = monitor.Success()
+		} else {
+			notice = monitor.Failure()
+		}
+
+		hasNotice := (notice != nil)
+		if hasNotice != s.expectNotice {
+			t.Errorf("step %d (notice), expected=%t actual=%t", i, s.expectNotice, hasNotice)
+		}
+
+		if monitor.IsUp() != s.expectUp {
+			t.Errorf("step %d (up), expected=%t actual=%t", i, s.expectUp, monitor.IsUp())
+		}
+
+		if hasNotice && notice.IsUp && notice.SuccessCount != 2 {
+			t.Errorf("step %d (success count), expected=%d actual=%d", i, 2, notice.SuccessCount)
+		}
+
+		// The recovery notice reports the success before the one that recovered the monitor
+		if hasNotice && notice.IsUp && (notice.LastSuccess.IsZero() || !notice.LastSuccess.Before(stepStart)) {
+			t.Errorf("step %d (last success), expected before=%v actual=%v", i, stepStart, notice.LastSuccess)
+		}
+	}
+}
+
+// TestMonitorFlapping tests that a monitor alternating between up and down
+// starts flapping, suppresses alerts and stops flapping once stable
+func TestMonitorFlapping(t *testing.T) {
+	monitor := m.Monitor{AlertAfter: 1, AlertEvery: Ptr(1), FlapThresholdHigh: 30, FlapThresholdLow: 15}
+	flappingNotices := 0
+	suppressedChecks := 0
+
+	// Alternate between failure and success
+	for i := 0; i < 12; i++ {
+		monitor.ShellCommand = []string{"false", "true"}[i%2]
+		_, notice := monitor.Check()
+
+		switch {
+		case notice != nil && notice.IsFlapping:
+			flappingNotices++
+		case notice == nil:
+			suppressedChecks++
+		}
+	}
+
+	if flappingNotices != 1 {
+		t.Errorf("flapping notices, expected=1 actual=%d", flappingNotices)
+	}
+
+	if suppressedChecks == 0 {
+		t.Errorf("expected notices to be suppressed while flapping")
+	}
+
+	if !monitor.IsFlapping() {
+		t.Errorf("expected monitor to be flapping, state change=%.1f", monitor.StateChangePercent())
+	}
+
+	// Stabilize until flapping stops
+	monitor.ShellCommand = "true"
+	for i := 0; i < 21 && monitor.IsFlapping(); i++ {
+		monitor.Check()
+	}
+
+	if monitor.IsFlapping() {
+		t.Errorf("expected monitor to stop flapping, state change=%.1f", monitor.StateChangePercent())
+	}
+
+	// Alerts resume after flapping stops
+	monitor.ShellCommand = "false"
+	if _, notice := monitor.Check(); notice == nil || notice.IsFlapping {
+		t.Errorf("expected regular down notice after flapping stopped, actual=%v", notice)
+	}
+}
+
 // TestMonitorFailureAlertAfter tests that alerts will not trigger until
 // hitting the threshold provided by AlertAfter
 func TestMonitorFailureAlertAfter(t *testing.T) {
@@ -265,6 +382,136 @@ func TestMonitorFailureExponential(t *testing.T) {
 	}
 }
 
+// TestMonitorTargets tests that a monitor with targets only fails once the quorum of targets fail
+func TestMonitorTargets(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		monitor        m.Monitor
+		expectSuccess  bool
+		expectFailed   []string
+		expectedOutput string
+		name           string
+	}{
+		{
+			m.Monitor{Targets: []string{"up-1", "down-1", "up-2"}, MinFailures: 2, ShellCommand: "echo {{.Target}}; case {{.Target}} in up-*) true;; *) false;; esac"},
+			true,
+			[]string{"down-1"},
+			"[up-1] up-1\n[down-1] down-1\n[up-2] up-2\n",
+			"Below min_failures",
+		},
+		{
+			m.Monitor{Targets: []string{"up-1", "down-1", "down-2"}, MinFailures: 2, ShellCommand: "echo {{.Target}}; case {{.Target}} in up-*) true;; *) false;; esac"},
+			false,
+			[]string{"down-1", "down-2"},
+			"[up-1] up-1\n[down-1] down-1\n[down-2] down-2\n",
+			"Reached min_failures",
+		},
+		{
+			m.Monitor{Targets: []string{"up-1", "up-2", "down-1"}, MinFailuresPercent: 30, Command: []string{"test", "{{.Target}}", "!=", "down-1"}},
+			false,
+			[]string{"down-1"},
+			"[up-1] \n[up-2] \n[down-1] \n",
+			"Reached min_failures_percent with command",
+		},
+		{
+			m.Monitor{Targets: []string{"up-1", "up-2", "down-1"}, MinFailuresPercent: 50, Command: []string{"test", "{{.Target}}", "!=", "down-1"}},
+			true,
+			[]string{"down-1"},
+			"[up-1] \n[up-2] \n[down-1] \n",
+			"Below min_failures_percent",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := c.monitor.Init(1, nil, []string{"log"}, nil, 1); err != nil {
+				t.Fatalf("Init(%v), unexpected error: %v", c.name, err)
+			}
+
+			if err := c.monitor.Validate(); err != nil {
+				t.Fatalf("Validate(%v), unexpected error: %v", c.name, err)
+			}
+
+			isSuccess, notice := c.monitor.Check()
+			if isSuccess != c.expectSuccess {
+				t.Errorf("Check(%v) (success), expected=%t actual=%t", c.name, c.expectSuccess, isSuccess)
+			}
+
+			if actual := c.monitor.LastOutput(); actual != c.expectedOutput {
+				t.Errorf("Check(%v) (output), expected=%q actual=%q", c.name, c.expectedOutput, actual)
+			}
+
+			if !c.expectSuccess {
+				if notice == nil {
+					t.Fatalf("Check(%v), expected an alert notice", c.name)
+				}
+
+				if !m.EqualSliceString(notice.FailedTargets, c.expectFailed) {
+					t.Errorf("Check(%v) (failed targets), expected=%v actual=%v", c.name, c.expectFailed, notice.FailedTargets)
+				}
+			}
+		})
+	}
+}
+
+// TestMonitorRetries tests that failed checks are retried within the same check
+func TestMonitorRetries(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		retries        int
+		expectSuccess  bool
+		expectAttempts int
+		expectOutput   []string
+		name           string
+	}{
+		{0, false, 1, []string{"attempt 1\n"}, "No retries"},
+		{1, false, 2, []string{"Attempt 1 of 2: exit status 1\nattempt 1\n", "Attempt 2 of 2: exit status 1\nattempt 2\n"}, "Retries exhausted"},
+		{3, true, 3, []string{"Attempt 1 of 4: exit status 1\nattempt 1\n", "Attempt 3 of 4: success\nattempt 3\n"}, "Succeeds on retry"},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Fails until the third attempt
+			counter := filepath.Join(t.TempDir(), "attempts")
+			monitor := m.Monitor{
+				Name:             c.name,
+				ShellCommand:     "echo x >> " + counter + "; n=$(wc -l < " + counter + "); echo attempt $n; test $n -ge 3",
+				Retries:          c.retries,
+				RetryIntervalStr: Ptr("1ms"),
+			}
+
+			if err := monitor.Init(1, nil, []string{"log"}, nil, 1); err != nil {
+				t.Fatalf("Init(%v), unexpected error: %v", c.name, err)
+			}
+
+			isSuccess, _ := monitor.Check()
+			if isSuccess != c.expectSuccess {
+				t.Errorf("Check(%v) (success), expected=%t actual=%t", c.name, c.expectSuccess, isSuccess)
+			}
+
+			if actual := monitor.LastCheckAttempts(); actual != c.expectAttempts {
+				t.Errorf("Check(%v) (attempts), expected=%d actual=%d", c.name, c.expectAttempts, actual)
+			}
+
+			for _, expected := range c.expectOutput {
+				if !strings.Contains(monitor.LastOutput(), expected) {
+					t.Errorf("Check(%v) (output), expected to contain %q, got %q", c.name, expected, monitor.LastOutput())
+				}
+			}
+		})
+	}
+}
+
 // TestMonitorCheck tests successful and failed commands and shell commands
 func TestMonitorCheck(t *testing.T) {
 	type expected struct {
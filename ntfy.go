@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// ErrInvalidNtfy indicates that an ntfy alert is not properly configured
+var ErrInvalidNtfy = errors.New("Invalid ntfy configuration")
+
+const (
+	ntfyMinPriority         = 1
+	ntfyMaxPriority         = 5
+	ntfyDefaultPriorityDown = 4
+	ntfyDefaultPriorityUp   = 3
+)
+
+// NtfyAlert is a built in alert type that publishes a push notification to an ntfy server
+type NtfyAlert struct {
+	URL        string   `hcl:"url"`
+	Topic      string   `hcl:"topic"`
+	Token      string   `hcl:"token,optional"`
+	Username   string   `hcl:"username,optional"`
+	Password   string   `hcl:"password,optional"`
+	Priority   *int     `hcl:"priority,optional"`
+	PriorityUp *int     `hcl:"priority_up,optional"`
+	Tags       []string `hcl:"tags,optional"`
+	Click      string   `hcl:"click,optional"`
+	Title      string   `hcl:"title,optional"`
+	Message    string   `hcl:"message,optional"`
+
+	clickTemplate   *template.Template
+	titleTemplate   *template.Template
+	messageTemplate *template.Template
+}
+
+type ntfyMessage struct {
+	Topic    string   `json:"topic"`
+	Title    string   `json:"title"`
+	Message  string   `json:"message"`
+	Priority int      `json:"priority"`
+	Tags     []string `json:"tags,omitempty"`
+	Click    string   `json:"click,omitempty"`
+}
+
+// Validate checks that the NtfyAlert is properly configured and returns errors if not
+func (ntfy NtfyAlert) Validate() error {
+	var err error
+
+	err = errors.Join(err, validateHTTPURL(ErrInvalidNtfy, "url", ntfy.URL))
+
+	if ntfy.Topic == "" {
+		err = errors.Join(err, fmt.Errorf("%w: topic is required", ErrInvalidNtfy))
+	}
+
+	if ntfy.Token != "" && ntfy.Username != "" {
+		err = errors.Join(err, fmt.Errorf("%w: only one of token or username can be configured", ErrInvalidNtfy))
+	}
+
+	if ntfy.Password != "" && ntfy.Username == "" {
+		err = errors.Join(err, fmt.Errorf("%w: password is configured without a username", ErrInvalidNtfy))
+	}
+
+	err = errors.Join(
+		err,
+		validatePriority(ErrInvalidNtfy, "priority", ntfy.Priority, ntfyMinPriority, ntfyMaxPriority),
+		validatePriority(ErrInvalidNtfy, "priority_up", ntfy.PriorityUp, ntfyMinPriority, ntfyMaxPriority),
+	)
+
+	return err
+}
+
+// BuildTemplates compiles the click, title and message templates for the NtfyAlert
+func (ntfy *NtfyAlert) BuildTemplates(name string, funcs template.FuncMap) error {
+	var err error
+
+	ntfy.clickTemplate, err = template.New(name + "-click").Funcs(funcs).Parse(ntfy.Click)
+	if err != nil {
+		return err
+	}
+
+	title := ntfy.Title
+	if title == "" {
+		title = defaultNoticeTitle
+	}
+
+	ntfy.titleTemplate, err = template.New(name + "-title").Funcs(funcs).Parse(title)
+	if err != nil {
+		return err
+	}
+
+	message := ntfy.Message
+	if message == "" {
+		message = defaultNoticeMessage
+	}
+
+	ntfy.messageTemplate, err = template.New(name + "-message").Funcs(funcs).Parse(message)
+
+	return err
+}
+
+// Send renders the notification and publishes it to the topic
+func (ntfy NtfyAlert) Send(notice AlertNotice) (string, error) {
+	if ntfy.clickTemplate == nil || ntfy.titleTemplate == nil || ntfy.messageTemplate == nil {
+		return "", fmt.Errorf("No templates compiled for ntfy: %w", errNoTemplate)
+	}
+
+	var click, title, message strings.Builder
+
+	if err := ntfy.clickTemplate.Execute(&click, notice); err != nil {
+		return "", err
+	}
+
+	if err := ntfy.titleTemplate.Execute(&title, notice); err != nil {
+		return "", err
+	}
+
+	if err := ntfy.messageTemplate.Execute(&message, notice); err != nil {
+		return "", err
+	}
+
+	headers := map[string]string{}
+
+	switch {
+	case ntfy.Token != "":
+		headers["Authorization"] = "Bearer " + ntfy.Token
+	case ntfy.Username != "":
+		credentials := base64.StdEncoding.EncodeToString([]byte(ntfy.Username + ":" + ntfy.Password))
+		headers["Authorization"] = "Basic " + credentials
+	}
+
+	// Publishing as json is done to the root of the server with the topic in the body
+	return postJSON(strings.TrimSuffix(ntfy.URL, "/"), headers, ntfyMessage{
+		Topic:    ntfy.Topic,
+		Title:    title.String(),
+		Message:  message.String(),
+		Priority: noticePriority(notice, ntfy.Priority, ntfy.PriorityUp, ntfyDefaultPriorityDown, ntfyDefaultPriorityUp),
+		Tags:     ntfy.Tags,
+		Click:    strings.TrimSpace(click.String()),
+	})
+}
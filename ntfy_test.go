@@ -0,0 +1,134 @@
+package main_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+
+	m "git.iamthefij.com/iamthefij/minitor-go/v2"
+)
+
+func TestNtfyAlertValidate(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		ntfy     m.NtfyAlert
+		expected error
+		name     string
+	}{
+		{m.NtfyAlert{URL: "https://ntfy.sh", Topic: "alerts"}, nil, "Valid"},
+		{m.NtfyAlert{URL: "https://ntfy.sh", Topic: "alerts", Username: "me", Password: "pw", Priority: Ptr(5), PriorityUp: Ptr(1)}, nil, "Valid auth and priority"},
+		{m.NtfyAlert{Topic: "alerts"}, m.ErrInvalidNtfy, "No url"},
+		{m.NtfyAlert{URL: "https://ntfy.sh"}, m.ErrInvalidNtfy, "No topic"},
+		{m.NtfyAlert{URL: "https://ntfy.sh", Topic: "alerts", Token: "tk", Username: "me"}, m.ErrInvalidNtfy, "Token and username"},
+		{m.NtfyAlert{URL: "https://ntfy.sh", Topic: "alerts", Password: "pw"}, m.ErrInvalidNtfy, "Password without username"},
+		{m.NtfyAlert{URL: "https://ntfy.sh", Topic: "alerts", Priority: Ptr(6)}, m.ErrInvalidNtfy, "Priority too high"},
+		{m.NtfyAlert{URL: "https://ntfy.sh", Topic: "alerts", PriorityUp: Ptr(0)}, m.ErrInvalidNtfy, "Priority up too low"},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := c.ntfy.Validate()
+			hasErr := (actual != nil)
+			expectErr := (c.expected != nil)
+
+			if hasErr != expectErr || !errors.Is(actual, c.expected) {
+				t.Errorf("Validate(%v), expected=%v actual=%v", c.name, c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestNtfyAlertSend(t *testing.T) {
+	t.Parallel()
+
+	type ntfyPayload struct {
+		Topic    string   `json:"topic"`
+		Title    string   `json:"title"`
+		Message  string   `json:"message"`
+		Priority int      `json:"priority"`
+		Tags     []string `json:"tags"`
+		Click    string   `json:"click"`
+	}
+
+	cases := []struct {
+		ntfy         m.NtfyAlert
+		notice       m.AlertNotice
+		expected     ntfyPayload
+		expectedAuth string
+		name         string
+	}{
+		{
+			m.NtfyAlert{Topic: "alerts", Token: "tk"},
+			m.AlertNotice{MonitorName: "web"},
+			ntfyPayload{
+				Topic:    "alerts",
+				Title:    "web is down",
+				Message:  "web is down after 0 failures. Last success was Mon, 01 Jan 0001 00:00:00 UTC.\n\n",
+				Priority: 4,
+			},
+			"Bearer tk",
+			"Down with defaults",
+		},
+		{
+			m.NtfyAlert{
+				Topic:      "alerts",
+				Username:   "me",
+				Password:   "pw",
+				PriorityUp: Ptr(2),
+				Tags:       []string{"white_check_mark"},
+				Click:      "https://status.example.com/{{.MonitorName}}",
+				Title:      "{{.MonitorName}} recovered",
+				Message:    "all good",
+			},
+			m.AlertNotice{MonitorName: "web", IsUp: true},
+			ntfyPayload{
+				Topic:    "alerts",
+				Title:    "web recovered",
+				Message:  "all good",
+				Priority: 2,
+				Tags:     []string{"white_check_mark"},
+				Click:    "https://status.example.com/web",
+			},
+			"Basic bWU6cHc=",
+			"Up with options",
+		},
+	}
+
+	for _, c := range cases {
+		server, requests := newWebhookServer(t, http.StatusOK)
+		alert := m.Alert{Name: "ntfy", Ntfy: &c.ntfy}
+		alert.Ntfy.URL = server.URL + "/"
+
+		if err := alert.BuildTemplates(); err != nil {
+			t.Fatalf("BuildTemplates(%v), unexpected error: %v", c.name, err)
+		}
+
+		if _, err := alert.Send(c.notice); err != nil {
+			t.Fatalf("Send(%v), unexpected error: %v", c.name, err)
+		}
+
+		request := <-requests
+
+		var payload ntfyPayload
+		if err := json.Unmarshal([]byte(request.body), &payload); err != nil {
+			t.Fatalf("Send(%v), invalid json body %q: %v", c.name, request.body, err)
+		}
+
+		if payload.Topic != c.expected.Topic || payload.Title != c.expected.Title ||
+			payload.Message != c.expected.Message || payload.Priority != c.expected.Priority ||
+			payload.Click != c.expected.Click || !slices.Equal(payload.Tags, c.expected.Tags) {
+			t.Errorf("Send(%v), expected=%+v actual=%+v", c.name, c.expected, payload)
+		}
+
+		if auth := request.header.Get("Authorization"); auth != c.expectedAuth {
+			t.Errorf("Send(%v), expected auth=%q actual=%q", c.name, c.expectedAuth, auth)
+		}
+	}
+}
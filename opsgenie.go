@@ -0,0 +1,287 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ErrInvalidOpsgenie indicates that an opsgenie alert is not properly configured
+var ErrInvalidOpsgenie = errors.New("Invalid opsgenie configuration")
+
+const (
+	defaultOpsgenieURL      = "https://api.opsgenie.com"
+	defaultOpsgeniePriority = "P3"
+
+	// opsgenieMaxMessageLength is the longest message accepted by the Alert API
+	opsgenieMaxMessageLength = 130
+	// opsgenieMaxDescriptionLength is the longest description accepted by the Alert API
+	opsgenieMaxDescriptionLength = 15000
+)
+
+var (
+	// opsgeniePriorities are the priorities accepted by the Alert API
+	opsgeniePriorities = []string{"P1", "P2", "P3", "P4", "P5"}
+	// opsgenieResponderTypes are the responder types accepted by the Alert API
+	opsgenieResponderTypes = []string{"team", "user", "escalation", "schedule"}
+)
+
+// OpsgenieResponder is a team, user, escalation or schedule that an Opsgenie alert is routed to
+type OpsgenieResponder struct {
+	Type string `hcl:"type"`
+	Name string `hcl:"name,optional"`
+	ID   string `hcl:"id,optional"`
+}
+
+// OpsgenieAlert is a built in alert type that creates Opsgenie alerts and closes them on recovery
+type OpsgenieAlert struct {
+	URL         string               `hcl:"url,optional"`
+	APIKey      string               `hcl:"api_key"`
+	Priority    string               `hcl:"priority,optional"`
+	Alias       string               `hcl:"alias,optional"`
+	Message     string               `hcl:"message,optional"`
+	Description string               `hcl:"description,optional"`
+	Responders  []*OpsgenieResponder `hcl:"responder,block"`
+	Tags        []string             `hcl:"tags,optional"`
+
+	aliasTemplate       *template.Template
+	messageTemplate     *template.Template
+	descriptionTemplate *template.Template
+
+	// open holds the aliases of monitors that are down so their alerts can be closed
+	open map[string]string
+}
+
+type opsgenieCreateRequest struct {
+	Message     string              `json:"message"`
+	Alias       string              `json:"alias"`
+	Description string              `json:"description,omitempty"`
+	Responders  []opsgenieRecipient `json:"responders,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Details     map[string]string   `json:"details"`
+	Source      string              `json:"source"`
+	Priority    string              `json:"priority"`
+}
+
+type opsgenieRecipient struct {
+	Type     string `json:"type"`
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+type opsgenieCloseRequest struct {
+	Source string `json:"source"`
+	Note   string `json:"note"`
+}
+
+// Validate checks that the OpsgenieAlert is properly configured and returns errors if not
+func (opsgenie OpsgenieAlert) Validate() error {
+	var err error
+
+	err = errors.Join(err, validateHTTPURL(ErrInvalidOpsgenie, "url", opsgenie.url()))
+
+	if opsgenie.APIKey == "" {
+		err = errors.Join(err, fmt.Errorf("%w: api_key is required", ErrInvalidOpsgenie))
+	}
+
+	if !slices.Contains(opsgeniePriorities, opsgenie.priority()) {
+		err = errors.Join(err, fmt.Errorf(
+			"%w: priority must be one of %s",
+			ErrInvalidOpsgenie,
+			strings.Join(opsgeniePriorities, ", "),
+		))
+	}
+
+	for i, responder := range opsgenie.Responders {
+		if !slices.Contains(opsgenieResponderTypes, responder.Type) {
+			err = errors.Join(err, fmt.Errorf(
+				"%w: responder %d type must be one of %s",
+				ErrInvalidOpsgenie,
+				i+1,
+				strings.Join(opsgenieResponderTypes, ", "),
+			))
+		}
+
+		if (responder.Name == "") == (responder.ID == "") {
+			err = errors.Join(err, fmt.Errorf(
+				"%w: responder %d must have exactly one of name or id configured",
+				ErrInvalidOpsgenie,
+				i+1,
+			))
+		}
+	}
+
+	return err
+}
+
+// BuildTemplates compiles the alias, message and description templates for the OpsgenieAlert
+func (opsgenie *OpsgenieAlert) BuildTemplates(name string, funcs template.FuncMap) error {
+	var err error
+
+	alias := opsgenie.Alias
+	if alias == "" {
+		alias = defaultNoticeKey
+	}
+
+	opsgenie.aliasTemplate, err = template.New(name + "-alias").Funcs(funcs).Parse(alias)
+	if err != nil {
+		return err
+	}
+
+	message := opsgenie.Message
+	if message == "" {
+		message = defaultNoticeTitle
+	}
+
+	opsgenie.messageTemplate, err = template.New(name + "-message").Funcs(funcs).Parse(message)
+	if err != nil {
+		return err
+	}
+
+	description := opsgenie.Description
+	if description == "" {
+		description = defaultNoticeMessage
+	}
+
+	opsgenie.descriptionTemplate, err = template.New(name + "-description").Funcs(funcs).Parse(description)
+
+	return err
+}
+
+// Send creates an alert for down notices and closes it when the monitor recovers. Digests of
+// grouped notices are sent as one request per monitor
+func (opsgenie *OpsgenieAlert) Send(notice AlertNotice) (string, error) {
+	if opsgenie.aliasTemplate == nil || opsgenie.messageTemplate == nil || opsgenie.descriptionTemplate == nil {
+		return "", fmt.Errorf("No templates compiled for opsgenie: %w", errNoTemplate)
+	}
+
+	if opsgenie.open == nil {
+		opsgenie.open = map[string]string{}
+	}
+
+	return sendEachNotice(notice, opsgenie.sendRequest)
+}
+
+// Resend closes the alerts of monitors that are up, as their recovery may have been suppressed
+// before reaching this alert
+func (opsgenie *OpsgenieAlert) Resend(_ time.Time, upMonitors map[string]bool) (string, error) {
+	outputs := []string{}
+
+	var err error
+
+	for _, monitorName := range slices.Sorted(maps.Keys(opsgenie.open)) {
+		if !upMonitors[monitorName] {
+			continue
+		}
+
+		output, closeErr := opsgenie.closeAlert(monitorName, opsgenie.open[monitorName])
+		outputs = append(outputs, output)
+
+		if closeErr != nil {
+			err = errors.Join(err, closeErr)
+
+			continue
+		}
+
+		delete(opsgenie.open, monitorName)
+	}
+
+	return strings.Join(outputs, "\n"), err
+}
+
+// sendRequest creates or closes the alert of a single monitor
+func (opsgenie *OpsgenieAlert) sendRequest(notice AlertNotice) (string, error) {
+	alias, err := renderTemplate(opsgenie.aliasTemplate, notice)
+	if err != nil {
+		return "", err
+	}
+
+	if notice.IsUp {
+		output, err := opsgenie.closeAlert(notice.MonitorName, alias)
+		if err == nil {
+			delete(opsgenie.open, notice.MonitorName)
+		}
+
+		return output, err
+	}
+
+	message, err := renderTemplate(opsgenie.messageTemplate, notice)
+	if err != nil {
+		return "", err
+	}
+
+	description, err := renderTemplate(opsgenie.descriptionTemplate, notice)
+	if err != nil {
+		return "", err
+	}
+
+	responders := []opsgenieRecipient{}
+
+	for _, responder := range opsgenie.Responders {
+		recipient := opsgenieRecipient{Type: responder.Type, ID: responder.ID, Name: responder.Name}
+		if responder.Type == "user" {
+			// Users are identified by username rather than name
+			recipient.Name, recipient.Username = "", responder.Name
+		}
+
+		responders = append(responders, recipient)
+	}
+
+	output, err := postJSON(opsgenie.alertsURL(), opsgenie.headers(), opsgenieCreateRequest{
+		Message:     truncateText(message, opsgenieMaxMessageLength),
+		Alias:       alias,
+		Description: truncateText(description, opsgenieMaxDescriptionLength),
+		Responders:  responders,
+		Tags:        opsgenie.Tags,
+		Details:     noticeDetails(notice),
+		Source:      "minitor",
+		Priority:    opsgenie.priority(),
+	})
+	if err != nil {
+		return output, err
+	}
+
+	opsgenie.open[notice.MonitorName] = alias
+
+	return output, nil
+}
+
+// closeAlert closes the alert with the given alias
+func (opsgenie OpsgenieAlert) closeAlert(monitorName, alias string) (string, error) {
+	closeURL := fmt.Sprintf("%s/%s/close?identifierType=alias", opsgenie.alertsURL(), url.PathEscape(alias))
+
+	return postJSON(closeURL, opsgenie.headers(), opsgenieCloseRequest{
+		Source: "minitor",
+		Note:   monitorName + " has recovered",
+	})
+}
+
+func (opsgenie OpsgenieAlert) alertsURL() string {
+	return strings.TrimSuffix(opsgenie.url(), "/") + "/v2/alerts"
+}
+
+func (opsgenie OpsgenieAlert) headers() map[string]string {
+	return map[string]string{"Authorization": "GenieKey " + opsgenie.APIKey}
+}
+
+func (opsgenie OpsgenieAlert) url() string {
+	if opsgenie.URL == "" {
+		return defaultOpsgenieURL
+	}
+
+	return opsgenie.URL
+}
+
+func (opsgenie OpsgenieAlert) priority() string {
+	if opsgenie.Priority == "" {
+		return defaultOpsgeniePriority
+	}
+
+	return strings.ToUpper(opsgenie.Priority)
+}
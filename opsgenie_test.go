@@ -0,0 +1,189 @@
+package main_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	m "git.iamthefij.com/iamthefij/minitor-go/v2"
+)
+
+func TestOpsgenieAlertValidate(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		opsgenie m.OpsgenieAlert
+		expected error
+		name     string
+	}{
+		{m.OpsgenieAlert{APIKey: "key"}, nil, "Valid with defaults"},
+		{
+			m.OpsgenieAlert{
+				APIKey:     "key",
+				URL:        "https://api.eu.opsgenie.com",
+				Priority:   "p1",
+				Responders: []*m.OpsgenieResponder{{Type: "team", Name: "ops"}, {Type: "user", ID: "1234"}},
+			},
+			nil,
+			"Valid",
+		},
+		{m.OpsgenieAlert{}, m.ErrInvalidOpsgenie, "No api key"},
+		{m.OpsgenieAlert{APIKey: "key", URL: "api.opsgenie.com"}, m.ErrInvalidOpsgenie, "Invalid url"},
+		{m.OpsgenieAlert{APIKey: "key", Priority: "P6"}, m.ErrInvalidOpsgenie, "Invalid priority"},
+		{m.OpsgenieAlert{APIKey: "key", Responders: []*m.OpsgenieResponder{{Type: "group", Name: "ops"}}}, m.ErrInvalidOpsgenie, "Invalid responder type"},
+		{m.OpsgenieAlert{APIKey: "key", Responders: []*m.OpsgenieResponder{{Type: "team"}}}, m.ErrInvalidOpsgenie, "Responder without name or id"},
+		{m.OpsgenieAlert{APIKey: "key", Responders: []*m.OpsgenieResponder{{Type: "team", Name: "ops", ID: "1"}}}, m.ErrInvalidOpsgenie, "Responder with name and id"},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := c.opsgenie.Validate()
+			hasErr := (actual != nil)
+			expectErr := (c.expected != nil)
+
+			if hasErr != expectErr || !errors.Is(actual, c.expected) {
+				t.Errorf("Validate(%v), expected=%v actual=%v", c.name, c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestOpsgenieAlertSend(t *testing.T) {
+	t.Parallel()
+
+	type opsgenieRequest struct {
+		uri  string
+		auth string
+		body string
+	}
+
+	requests := make(chan opsgenieRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		requests <- opsgenieRequest{uri: r.URL.RequestURI(), auth: r.Header.Get("Authorization"), body: string(body)}
+
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	t.Cleanup(server.Close)
+
+	alert := m.Alert{Name: "opsgenie", Opsgenie: &m.OpsgenieAlert{
+		URL:        server.URL + "/",
+		APIKey:     "api-key",
+		Priority:   "P2",
+		Responders: []*m.OpsgenieResponder{{Type: "team", Name: "ops"}, {Type: "user", Name: "me@example.com"}},
+		Tags:       []string{"minitor"},
+	}}
+
+	if err := alert.BuildTemplates(); err != nil {
+		t.Fatalf("BuildTemplates(), unexpected error: %v", err)
+	}
+
+	// Create an alert when down
+	if _, err := alert.Send(m.AlertNotice{MonitorName: "web app", FailureCount: 3, LastCheckOutput: "refused"}); err != nil {
+		t.Fatalf("Send(down), unexpected error: %v", err)
+	}
+
+	request := <-requests
+	if request.uri != "/v2/alerts" || request.auth != "GenieKey api-key" {
+		t.Errorf("Send(down), unexpected uri=%q auth=%q", request.uri, request.auth)
+	}
+
+	var created struct {
+		Message    string            `json:"message"`
+		Alias      string            `json:"alias"`
+		Priority   string            `json:"priority"`
+		Tags       []string          `json:"tags"`
+		Details    map[string]string `json:"details"`
+		Responders []struct {
+			Type     string `json:"type"`
+			Name     string `json:"name"`
+			Username string `json:"username"`
+		} `json:"responders"`
+	}
+
+	if err := json.Unmarshal([]byte(request.body), &created); err != nil {
+		t.Fatalf("Send(down), invalid json body %q: %v", request.body, err)
+	}
+
+	if created.Message != "web app is down" || created.Alias != "minitor/web app" || created.Priority != "P2" ||
+		len(created.Tags) != 1 || created.Details["failure_count"] != "3" || created.Details["last_check_output"] != "refused" {
+		t.Errorf("Send(down), unexpected body %q", request.body)
+	}
+
+	if len(created.Responders) != 2 || created.Responders[0].Name != "ops" ||
+		created.Responders[1].Username != "me@example.com" || created.Responders[1].Name != "" {
+		t.Errorf("Send(down), unexpected responders %q", request.body)
+	}
+
+	// Close the alert by alias when up
+	if _, err := alert.Send(m.AlertNotice{MonitorName: "web app", IsUp: true}); err != nil {
+		t.Fatalf("Send(up), unexpected error: %v", err)
+	}
+
+	request = <-requests
+	if request.uri != "/v2/alerts/minitor%2Fweb%20app/close?identifierType=alias" || request.auth != "GenieKey api-key" {
+		t.Errorf("Send(up), unexpected uri=%q auth=%q", request.uri, request.auth)
+	}
+
+	// Send each monitor of a digest separately
+	digest := m.NewDigestNotice([]m.AlertNotice{{MonitorName: "web"}, {MonitorName: "db", IsUp: true}})
+	if _, err := alert.Send(digest); err != nil {
+		t.Fatalf("Send(digest), unexpected error: %v", err)
+	}
+
+	request = <-requests
+	if request.uri != "/v2/alerts" || !strings.Contains(request.body, `"alias":"minitor/web"`) {
+		t.Errorf("Send(digest), unexpected create uri=%q body=%q", request.uri, request.body)
+	}
+
+	request = <-requests
+	if request.uri != "/v2/alerts/minitor%2Fdb/close?identifierType=alias" {
+		t.Errorf("Send(digest), unexpected close uri=%q", request.uri)
+	}
+}
+
+func TestOpsgenieAlertCloseSuppressedRecovery(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newWebhookServer(t, http.StatusAccepted)
+	alert := &m.Alert{Name: "opsgenie", Opsgenie: &m.OpsgenieAlert{URL: server.URL, APIKey: "api-key"}}
+	config := m.Config{Alerts: []*m.Alert{alert}, Monitors: []*m.Monitor{{Name: "web"}, {Name: "db", AlertCount: 1}}}
+
+	if err := alert.BuildTemplates(); err != nil {
+		t.Fatalf("BuildTemplates(), unexpected error: %v", err)
+	}
+
+	for _, monitorName := range []string{"web", "db"} {
+		if _, err := alert.Send(m.AlertNotice{MonitorName: monitorName}); err != nil {
+			t.Fatalf("Send(%s), unexpected error: %v", monitorName, err)
+		}
+
+		<-requests
+	}
+
+	// The recovery of web never reached the alert, so its alert is closed once it is up
+	for i := 0; i < 2; i++ {
+		if err := m.ResendFiringAlerts(&config, time.Now()); err != nil {
+			t.Fatalf("ResendFiringAlerts(), unexpected error: %v", err)
+		}
+	}
+
+	if len(requests) != 1 {
+		t.Fatalf("ResendFiringAlerts(), expected a single close request, got %d requests", len(requests))
+	}
+
+	if request := <-requests; !strings.Contains(request.body, "web has recovered") {
+		t.Errorf("ResendFiringAlerts(), expected web to be closed, got %q", request.body)
+	}
+}
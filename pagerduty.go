@@ -0,0 +1,268 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ErrInvalidPagerDuty indicates that a pagerduty alert is not properly configured
+var ErrInvalidPagerDuty = errors.New("Invalid pagerduty configuration")
+
+const (
+	defaultPagerDutyURL      = "https://events.pagerduty.com/v2/enqueue"
+	defaultPagerDutySeverity = "error"
+	defaultPagerDutySource   = "minitor"
+
+	// pagerDutyMaxSummaryLength is the longest summary accepted by the Events API
+	pagerDutyMaxSummaryLength = 1024
+)
+
+// pagerDutySeverities are the severities accepted by the Events API
+var pagerDutySeverities = []string{"critical", "error", "warning", "info"}
+
+// PagerDutyAlert is a built in alert type that triggers and resolves incidents with the PagerDuty Events API v2
+type PagerDutyAlert struct {
+	URL           string            `hcl:"url,optional"`
+	RoutingKey    string            `hcl:"routing_key"`
+	Severity      string            `hcl:"severity,optional"`
+	DedupKey      string            `hcl:"dedup_key,optional"`
+	Summary       string            `hcl:"summary,optional"`
+	Source        string            `hcl:"source,optional"`
+	Component     string            `hcl:"component,optional"`
+	Group         string            `hcl:"group,optional"`
+	Class         string            `hcl:"class,optional"`
+	CustomDetails map[string]string `hcl:"custom_details,optional"`
+
+	dedupKeyTemplate      *template.Template
+	summaryTemplate       *template.Template
+	sourceTemplate        *template.Template
+	componentTemplate     *template.Template
+	customDetailTemplates map[string]*template.Template
+
+	// triggered holds the dedup keys of monitors that are down so they can be resolved
+	triggered map[string]string
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Client      string            `json:"client"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Timestamp     string            `json:"timestamp"`
+	Component     string            `json:"component,omitempty"`
+	Group         string            `json:"group,omitempty"`
+	Class         string            `json:"class,omitempty"`
+	CustomDetails map[string]string `json:"custom_details"`
+}
+
+// Validate checks that the PagerDutyAlert is properly configured and returns errors if not
+func (pagerDuty PagerDutyAlert) Validate() error {
+	var err error
+
+	err = errors.Join(err, validateHTTPURL(ErrInvalidPagerDuty, "url", pagerDuty.url()))
+
+	if pagerDuty.RoutingKey == "" {
+		err = errors.Join(err, fmt.Errorf("%w: routing_key is required", ErrInvalidPagerDuty))
+	}
+
+	if !slices.Contains(pagerDutySeverities, pagerDuty.severity()) {
+		err = errors.Join(err, fmt.Errorf(
+			"%w: severity must be one of %s",
+			ErrInvalidPagerDuty,
+			strings.Join(pagerDutySeverities, ", "),
+		))
+	}
+
+	return err
+}
+
+// BuildTemplates compiles the dedup key, summary, source, component and custom detail templates
+func (pagerDuty *PagerDutyAlert) BuildTemplates(name string, funcs template.FuncMap) error {
+	var err error
+
+	for _, tmpl := range []struct {
+		target      **template.Template
+		key         string
+		value       string
+		defaultText string
+	}{
+		{&pagerDuty.dedupKeyTemplate, "dedup_key", pagerDuty.DedupKey, defaultNoticeKey},
+		{&pagerDuty.summaryTemplate, "summary", pagerDuty.Summary, defaultNoticeTitle},
+		{&pagerDuty.sourceTemplate, "source", pagerDuty.Source, defaultPagerDutySource},
+		{&pagerDuty.componentTemplate, "component", pagerDuty.Component, ""},
+	} {
+		value := tmpl.value
+		if value == "" {
+			value = tmpl.defaultText
+		}
+
+		*tmpl.target, err = template.New(name + "-" + tmpl.key).Funcs(funcs).Parse(value)
+		if err != nil {
+			return err
+		}
+	}
+
+	pagerDuty.customDetailTemplates = map[string]*template.Template{}
+
+	for key, value := range pagerDuty.CustomDetails {
+		pagerDuty.customDetailTemplates[key], err = template.New(name + "-" + key).Funcs(funcs).Parse(value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Send triggers an event for down notices and resolves it when the monitor recovers. Digests
+// of grouped notices are sent as one event per monitor
+func (pagerDuty *PagerDutyAlert) Send(notice AlertNotice) (string, error) {
+	if pagerDuty.dedupKeyTemplate == nil || pagerDuty.summaryTemplate == nil {
+		return "", fmt.Errorf("No templates compiled for pagerduty: %w", errNoTemplate)
+	}
+
+	if pagerDuty.triggered == nil {
+		pagerDuty.triggered = map[string]string{}
+	}
+
+	return sendEachNotice(notice, pagerDuty.sendEvent)
+}
+
+// Resend resolves the incidents of monitors that are up, as their recovery may have been
+// suppressed before reaching this alert
+func (pagerDuty *PagerDutyAlert) Resend(_ time.Time, upMonitors map[string]bool) (string, error) {
+	outputs := []string{}
+
+	var err error
+
+	for _, monitorName := range slices.Sorted(maps.Keys(pagerDuty.triggered)) {
+		if !upMonitors[monitorName] {
+			continue
+		}
+
+		output, sendErr := pagerDuty.post(pagerDuty.resolveEvent(pagerDuty.triggered[monitorName]))
+		outputs = append(outputs, output)
+
+		if sendErr != nil {
+			err = errors.Join(err, sendErr)
+
+			continue
+		}
+
+		delete(pagerDuty.triggered, monitorName)
+	}
+
+	return strings.Join(outputs, "\n"), err
+}
+
+// sendEvent triggers or resolves the event of a single monitor
+func (pagerDuty *PagerDutyAlert) sendEvent(notice AlertNotice) (string, error) {
+	dedupKey, err := renderTemplate(pagerDuty.dedupKeyTemplate, notice)
+	if err != nil {
+		return "", err
+	}
+
+	event := pagerDuty.resolveEvent(dedupKey)
+
+	if !notice.IsUp {
+		event.EventAction = "trigger"
+
+		event.Payload, err = pagerDuty.buildPayload(notice)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	output, err := pagerDuty.post(event)
+	if err != nil {
+		return output, err
+	}
+
+	if notice.IsUp {
+		delete(pagerDuty.triggered, notice.MonitorName)
+	} else {
+		pagerDuty.triggered[notice.MonitorName] = dedupKey
+	}
+
+	return output, nil
+}
+
+// resolveEvent returns an event resolving the incident with the dedup key
+func (pagerDuty PagerDutyAlert) resolveEvent(dedupKey string) pagerDutyEvent {
+	return pagerDutyEvent{
+		RoutingKey:  pagerDuty.RoutingKey,
+		EventAction: "resolve",
+		DedupKey:    dedupKey,
+		Client:      "minitor",
+	}
+}
+
+func (pagerDuty PagerDutyAlert) post(event pagerDutyEvent) (string, error) {
+	return postJSON(pagerDuty.url(), nil, event)
+}
+
+// buildPayload renders the details of a trigger event
+func (pagerDuty PagerDutyAlert) buildPayload(notice AlertNotice) (*pagerDutyPayload, error) {
+	summary, err := renderTemplate(pagerDuty.summaryTemplate, notice)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := renderTemplate(pagerDuty.sourceTemplate, notice)
+	if err != nil {
+		return nil, err
+	}
+
+	component, err := renderTemplate(pagerDuty.componentTemplate, notice)
+	if err != nil {
+		return nil, err
+	}
+
+	customDetails := noticeDetails(notice)
+
+	for key, tmpl := range pagerDuty.customDetailTemplates {
+		customDetails[key], err = renderTemplate(tmpl, notice)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &pagerDutyPayload{
+		Summary:       truncateText(summary, pagerDutyMaxSummaryLength),
+		Source:        source,
+		Severity:      pagerDuty.severity(),
+		Timestamp:     time.Now().UTC().Format(time.RFC3339),
+		Component:     component,
+		Group:         pagerDuty.Group,
+		Class:         pagerDuty.Class,
+		CustomDetails: customDetails,
+	}, nil
+}
+
+func (pagerDuty PagerDutyAlert) url() string {
+	if pagerDuty.URL == "" {
+		return defaultPagerDutyURL
+	}
+
+	return pagerDuty.URL
+}
+
+func (pagerDuty PagerDutyAlert) severity() string {
+	if pagerDuty.Severity == "" {
+		return defaultPagerDutySeverity
+	}
+
+	return strings.ToLower(pagerDuty.Severity)
+}
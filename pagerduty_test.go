@@ -0,0 +1,222 @@
+package main_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	m "git.iamthefij.com/iamthefij/minitor-go/v2"
+)
+
+func TestPagerDutyAlertValidate(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		pagerDuty m.PagerDutyAlert
+		expected  error
+		name      string
+	}{
+		{m.PagerDutyAlert{RoutingKey: "key"}, nil, "Valid with defaults"},
+		{m.PagerDutyAlert{RoutingKey: "key", URL: "https://events.eu.pagerduty.com/v2/enqueue", Severity: "Warning"}, nil, "Valid"},
+		{m.PagerDutyAlert{}, m.ErrInvalidPagerDuty, "No routing key"},
+		{m.PagerDutyAlert{RoutingKey: "key", URL: "events.pagerduty.com"}, m.ErrInvalidPagerDuty, "Invalid url"},
+		{m.PagerDutyAlert{RoutingKey: "key", Severity: "fatal"}, m.ErrInvalidPagerDuty, "Invalid severity"},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := c.pagerDuty.Validate()
+			hasErr := (actual != nil)
+			expectErr := (c.expected != nil)
+
+			if hasErr != expectErr || !errors.Is(actual, c.expected) {
+				t.Errorf("Validate(%v), expected=%v actual=%v", c.name, c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestPagerDutyAlertSend(t *testing.T) {
+	t.Parallel()
+
+	type pagerDutyEvent struct {
+		RoutingKey  string `json:"routing_key"`
+		EventAction string `json:"event_action"`
+		DedupKey    string `json:"dedup_key"`
+		Payload     *struct {
+			Summary       string            `json:"summary"`
+			Source        string            `json:"source"`
+			Severity      string            `json:"severity"`
+			Component     string            `json:"component"`
+			CustomDetails map[string]string `json:"custom_details"`
+		} `json:"payload"`
+	}
+
+	server, requests := newWebhookServer(t, http.StatusAccepted)
+	alert := m.Alert{Name: "pagerduty", PagerDuty: &m.PagerDutyAlert{
+		URL:           server.URL,
+		RoutingKey:    "routing-key",
+		Severity:      "critical",
+		Component:     "{{.MonitorName}}-component",
+		CustomDetails: map[string]string{"runbook": "https://wiki.example.com/{{.MonitorName}}"},
+	}}
+
+	if err := alert.BuildTemplates(); err != nil {
+		t.Fatalf("BuildTemplates(), unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		notice         m.AlertNotice
+		expectedAction string
+		name           string
+	}{
+		{m.AlertNotice{MonitorName: "web", FailureCount: 2, LastCheckOutput: "refused"}, "trigger", "Trigger"},
+		{m.AlertNotice{MonitorName: "web", IsUp: true}, "resolve", "Resolve"},
+	}
+
+	for _, c := range cases {
+		if _, err := alert.Send(c.notice); err != nil {
+			t.Fatalf("Send(%v), unexpected error: %v", c.name, err)
+		}
+
+		request := <-requests
+
+		var event pagerDutyEvent
+		if err := json.Unmarshal([]byte(request.body), &event); err != nil {
+			t.Fatalf("Send(%v), invalid json body %q: %v", c.name, request.body, err)
+		}
+
+		if event.RoutingKey != "routing-key" || event.EventAction != c.expectedAction || event.DedupKey != "minitor/web" {
+			t.Errorf("Send(%v), unexpected event %q", c.name, request.body)
+		}
+
+		if c.notice.IsUp {
+			if event.Payload != nil {
+				t.Errorf("Send(%v), expected no payload for resolve, got %q", c.name, request.body)
+			}
+
+			continue
+		}
+
+		if event.Payload == nil {
+			t.Fatalf("Send(%v), expected payload for trigger, got %q", c.name, request.body)
+		}
+
+		payload := event.Payload
+		if payload.Summary != "web is down" || payload.Source != "minitor" ||
+			payload.Severity != "critical" || payload.Component != "web-component" {
+			t.Errorf("Send(%v), unexpected payload %q", c.name, request.body)
+		}
+
+		for key, expected := range map[string]string{
+			"failure_count":     "2",
+			"last_check_output": "refused",
+			"runbook":           "https://wiki.example.com/web",
+		} {
+			if payload.CustomDetails[key] != expected {
+				t.Errorf("Send(%v), expected custom detail %s=%q, got %q", c.name, key, expected, payload.CustomDetails[key])
+			}
+		}
+	}
+}
+
+func TestPagerDutyAlertSendDigest(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newWebhookServer(t, http.StatusAccepted)
+	alert := m.Alert{Name: "pagerduty", PagerDuty: &m.PagerDutyAlert{URL: server.URL, RoutingKey: "routing-key"}}
+
+	if err := alert.BuildTemplates(); err != nil {
+		t.Fatalf("BuildTemplates(), unexpected error: %v", err)
+	}
+
+	digest := m.NewDigestNotice([]m.AlertNotice{{MonitorName: "web"}, {MonitorName: "db", IsUp: true}})
+	if _, err := alert.Send(digest); err != nil {
+		t.Fatalf("Send(), unexpected error: %v", err)
+	}
+
+	for _, expected := range []struct {
+		action   string
+		dedupKey string
+	}{{"trigger", "minitor/web"}, {"resolve", "minitor/db"}} {
+		request := <-requests
+
+		var event struct {
+			EventAction string `json:"event_action"`
+			DedupKey    string `json:"dedup_key"`
+		}
+		if err := json.Unmarshal([]byte(request.body), &event); err != nil {
+			t.Fatalf("Send(), invalid json body %q: %v", request.body, err)
+		}
+
+		if event.EventAction != expected.action || event.DedupKey != expected.dedupKey {
+			t.Errorf("Send(), expected %s of %s, got %q", expected.action, expected.dedupKey, request.body)
+		}
+	}
+}
+
+func TestPagerDutyAlertResolveSuppressedRecovery(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newWebhookServer(t, http.StatusAccepted)
+	alert := &m.Alert{Name: "pagerduty", PagerDuty: &m.PagerDutyAlert{URL: server.URL, RoutingKey: "routing-key"}}
+	config := m.Config{Alerts: []*m.Alert{alert}, Monitors: []*m.Monitor{{Name: "web"}, {Name: "db", AlertCount: 1}}}
+
+	if err := alert.BuildTemplates(); err != nil {
+		t.Fatalf("BuildTemplates(), unexpected error: %v", err)
+	}
+
+	for _, monitorName := range []string{"web", "db"} {
+		if _, err := alert.Send(m.AlertNotice{MonitorName: monitorName}); err != nil {
+			t.Fatalf("Send(%s), unexpected error: %v", monitorName, err)
+		}
+
+		<-requests
+	}
+
+	// The recovery of web never reached the alert, so its incident is resolved once it is up
+	for i := 0; i < 2; i++ {
+		if err := m.ResendFiringAlerts(&config, time.Now()); err != nil {
+			t.Fatalf("ResendFiringAlerts(), unexpected error: %v", err)
+		}
+	}
+
+	if len(requests) != 1 {
+		t.Fatalf("ResendFiringAlerts(), expected a single resolve event, got %d requests", len(requests))
+	}
+
+	request := <-requests
+
+	var event struct {
+		EventAction string `json:"event_action"`
+		DedupKey    string `json:"dedup_key"`
+	}
+	if err := json.Unmarshal([]byte(request.body), &event); err != nil {
+		t.Fatalf("ResendFiringAlerts(), invalid json body %q: %v", request.body, err)
+	}
+
+	if event.EventAction != "resolve" || event.DedupKey != "minitor/web" {
+		t.Errorf("ResendFiringAlerts(), expected resolve of minitor/web, got %q", request.body)
+	}
+}
+
+func TestPagerDutyAlertSendFailed(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newWebhookServer(t, http.StatusBadRequest)
+	alert := m.Alert{Name: "pagerduty", PagerDuty: &m.PagerDutyAlert{URL: server.URL, RoutingKey: "bad"}}
+
+	if err := alert.BuildTemplates(); err != nil {
+		t.Fatalf("BuildTemplates(), unexpected error: %v", err)
+	}
+
+	if _, err := alert.Send(m.AlertNotice{MonitorName: "web"}); !errors.Is(err, m.ErrAlertFailed) {
+		t.Errorf("Send(), expected=%v actual=%v", m.ErrAlertFailed, err)
+	}
+}
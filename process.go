@@ -0,0 +1,241 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	procPath = "/proc"
+	// clockTicks is the USER_HZ value used by the kernel to report cpu times in /proc
+	clockTicks      = 100
+	bytesPerKiB     = 1024
+	bytesPerMiB     = 1024 * 1024
+	procStatUTime   = 11
+	procStatSTime   = 12
+	percentMultiple = 100
+)
+
+// ProcessCheck is a config driven check that matching processes are running
+type ProcessCheck struct {
+	PidFile       string   `hcl:"pid_file,optional"`
+	Name          string   `hcl:"name,optional"`
+	Cmdline       string   `hcl:"cmdline,optional"`
+	MinCount      *int     `hcl:"min_count,optional"`
+	MaxCount      *int     `hcl:"max_count,optional"`
+	MaxMemoryMB   *int     `hcl:"max_memory_mb,optional"`
+	MaxCPUPercent *float64 `hcl:"max_cpu_percent,optional"`
+
+	cmdlineRegexp *regexp.Regexp
+	cpuSamples    map[int]processCPUSample
+}
+
+type processCPUSample struct {
+	cpuTime time.Duration
+	sampled time.Time
+}
+
+// processInfo is the state of a single process read from /proc
+type processInfo struct {
+	pid      int
+	name     string
+	cmdline  string
+	rssBytes int64
+	cpuTime  time.Duration
+}
+
+// Init compiles the cmdline pattern and applies defaults for the ProcessCheck
+func (check *ProcessCheck) Init() error {
+	if check.PidFile == "" && check.Name == "" && check.Cmdline == "" {
+		return fmt.Errorf("%w: process check needs at least one of pid_file, name or cmdline", ErrInvalidMonitor)
+	}
+
+	if check.MinCount == nil {
+		defaultMinCount := 1
+		check.MinCount = &defaultMinCount
+	}
+
+	if check.Cmdline != "" {
+		var err error
+
+		check.cmdlineRegexp, err = regexp.Compile(check.Cmdline)
+		if err != nil {
+			return fmt.Errorf("%w: invalid process cmdline pattern: %w", ErrInvalidMonitor, err)
+		}
+	}
+
+	check.cpuSamples = map[int]processCPUSample{}
+
+	return nil
+}
+
+// Check finds processes matching the configured selectors and verifies counts and resource usage.
+// CPU usage is measured between consecutive checks, so it is not evaluated on the first check.
+func (check *ProcessCheck) Check() (string, error) {
+	processes, err := check.findProcesses()
+	if err != nil {
+		return "", err
+	}
+
+	var output strings.Builder
+
+	pids := make([]string, 0, len(processes))
+	for _, process := range processes {
+		pids = append(pids, strconv.Itoa(process.pid))
+	}
+
+	fmt.Fprintf(&output, "Matched %d processes: %s\n", len(processes), strings.Join(pids, ", "))
+
+	if len(processes) < *check.MinCount {
+		err = errors.Join(err, fmt.Errorf(
+			"%w: found %d matching processes, expected at least %d", ErrCheckFailed, len(processes), *check.MinCount,
+		))
+	}
+
+	if check.MaxCount != nil && len(processes) > *check.MaxCount {
+		err = errors.Join(err, fmt.Errorf(
+			"%w: found %d matching processes, expected at most %d", ErrCheckFailed, len(processes), *check.MaxCount,
+		))
+	}
+
+	now := time.Now()
+	samples := map[int]processCPUSample{}
+
+	for _, process := range processes {
+		memoryMB := float64(process.rssBytes) / bytesPerMiB
+		fmt.Fprintf(&output, "%d %s: memory=%.1fMB", process.pid, process.name, memoryMB)
+
+		if check.MaxMemoryMB != nil && memoryMB > float64(*check.MaxMemoryMB) {
+			err = errors.Join(err, fmt.Errorf(
+				"%w: process %d is using %.1fMB of memory, expected at most %dMB",
+				ErrCheckFailed, process.pid, memoryMB, *check.MaxMemoryMB,
+			))
+		}
+
+		samples[process.pid] = processCPUSample{cpuTime: process.cpuTime, sampled: now}
+
+		if previous, ok := check.cpuSamples[process.pid]; ok {
+			elapsed := now.Sub(previous.sampled)
+			cpuPercent := float64(process.cpuTime-previous.cpuTime) / float64(elapsed) * percentMultiple
+			fmt.Fprintf(&output, " cpu=%.1f%%", cpuPercent)
+
+			if check.MaxCPUPercent != nil && cpuPercent > *check.MaxCPUPercent {
+				err = errors.Join(err, fmt.Errorf(
+					"%w: process %d is using %.1f%% cpu, expected at most %.1f%%",
+					ErrCheckFailed, process.pid, cpuPercent, *check.MaxCPUPercent,
+				))
+			}
+		}
+
+		output.WriteString("\n")
+	}
+
+	check.cpuSamples = samples
+
+	return output.String(), err
+}
+
+// findProcesses returns all processes matching every configured selector
+func (check ProcessCheck) findProcesses() ([]processInfo, error) {
+	var pids []int
+
+	if check.PidFile != "" {
+		content, err := os.ReadFile(check.PidFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to read pid file: %w", ErrCheckFailed, err)
+		}
+
+		pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid pid file %s: %w", ErrCheckFailed, check.PidFile, err)
+		}
+
+		pids = []int{pid}
+	} else {
+		entries, err := os.ReadDir(procPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list processes: %w", err)
+		}
+
+		for _, entry := range entries {
+			if pid, err := strconv.Atoi(entry.Name()); err == nil {
+				pids = append(pids, pid)
+			}
+		}
+	}
+
+	processes := []processInfo{}
+
+	for _, pid := range pids {
+		process, err := readProcessInfo(pid)
+		if err != nil {
+			// Process has likely exited since listing
+			continue
+		}
+
+		if check.Name != "" && process.name != check.Name {
+			continue
+		}
+
+		if check.cmdlineRegexp != nil && !check.cmdlineRegexp.MatchString(process.cmdline) {
+			continue
+		}
+
+		processes = append(processes, process)
+	}
+
+	return processes, nil
+}
+
+// readProcessInfo reads name, command line, memory and cpu time for a pid from /proc
+func readProcessInfo(pid int) (processInfo, error) {
+	process := processInfo{pid: pid}
+	processPath := filepath.Join(procPath, strconv.Itoa(pid))
+
+	comm, err := os.ReadFile(filepath.Join(processPath, "comm"))
+	if err != nil {
+		return process, fmt.Errorf("failed to read process name: %w", err)
+	}
+
+	process.name = strings.TrimSpace(string(comm))
+
+	cmdline, err := os.ReadFile(filepath.Join(processPath, "cmdline"))
+	if err != nil {
+		return process, fmt.Errorf("failed to read process cmdline: %w", err)
+	}
+
+	process.cmdline = strings.TrimSpace(strings.ReplaceAll(string(cmdline), "\x00", " "))
+
+	status, err := os.ReadFile(filepath.Join(processPath, "status"))
+	if err != nil {
+		return process, fmt.Errorf("failed to read process status: %w", err)
+	}
+
+	for _, line := range strings.Split(string(status), "\n") {
+		if value, ok := strings.CutPrefix(line, "VmRSS:"); ok {
+			kib, _ := strconv.ParseInt(strings.TrimSuffix(strings.TrimSpace(value), " kB"), 10, 64)
+			process.rssBytes = kib * bytesPerKiB
+		}
+	}
+
+	stat, err := os.ReadFile(filepath.Join(processPath, "stat"))
+	if err != nil {
+		return process, fmt.Errorf("failed to read process stat: %w", err)
+	}
+
+	// The process name in stat may contain spaces, so fields are counted after its closing paren
+	statFields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	if len(statFields) > procStatSTime {
+		utime, _ := strconv.ParseInt(statFields[procStatUTime], 10, 64)
+		stime, _ := strconv.ParseInt(statFields[procStatSTime], 10, 64)
+		process.cpuTime = time.Duration(utime+stime) * time.Second / clockTicks
+	}
+
+	return process, nil
+}
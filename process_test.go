@@ -0,0 +1,139 @@
+package main_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	m "git.iamthefij.com/iamthefij/minitor-go/v2"
+)
+
+// startSleeper starts a long running process that can be matched by its command line
+func startSleeper(t *testing.T, seconds string) *exec.Cmd {
+	t.Helper()
+
+	cmd := exec.Command("sleep", seconds)
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start process: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	return cmd
+}
+
+func TestProcessCheck(t *testing.T) {
+	t.Parallel()
+
+	sleeper := startSleeper(t, "31.4159")
+
+	pidFile := filepath.Join(t.TempDir(), "sleep.pid")
+	if err := os.WriteFile(pidFile, []byte(strconv.Itoa(sleeper.Process.Pid)+"\n"), 0o600); err != nil {
+		t.Fatalf("failed to write pid file: %v", err)
+	}
+
+	cases := []struct {
+		check     m.ProcessCheck
+		expectErr bool
+		name      string
+	}{
+		{m.ProcessCheck{PidFile: pidFile}, false, "Pid file"},
+		{m.ProcessCheck{PidFile: "./does-not-exist.pid"}, true, "Missing pid file"},
+		{m.ProcessCheck{Cmdline: `^sleep 31\.4159$`}, false, "Cmdline"},
+		{m.ProcessCheck{Name: "sleep", Cmdline: `31\.4159`}, false, "Name and cmdline"},
+		{m.ProcessCheck{Name: "not-sleep", Cmdline: `31\.4159`}, true, "Name mismatch"},
+		{m.ProcessCheck{Cmdline: `^sleep 31\.4159$`, MinCount: Ptr(2)}, true, "Below min count"},
+		{m.ProcessCheck{Cmdline: `^sleep 31\.4159$`, MaxCount: Ptr(0)}, true, "Above max count"},
+		{m.ProcessCheck{Cmdline: `^nothing matches this$`, MinCount: Ptr(0), MaxCount: Ptr(0)}, false, "Expected absent"},
+		{m.ProcessCheck{PidFile: pidFile, MaxMemoryMB: Ptr(1024)}, false, "Below memory threshold"},
+		{m.ProcessCheck{PidFile: pidFile, MaxMemoryMB: Ptr(0)}, true, "Above memory threshold"},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := c.check.Init(); err != nil {
+				t.Fatalf("Init(%v), unexpected error: %v", c.name, err)
+			}
+
+			output, err := c.check.Check()
+			hasErr := (err != nil)
+
+			if hasErr != c.expectErr {
+				t.Errorf("Check(%v), expected_error=%t actual=%v output=%s", c.name, c.expectErr, err, output)
+			}
+		})
+	}
+}
+
+func TestProcessCheckOutputAndCPU(t *testing.T) {
+	t.Parallel()
+
+	sleeper := startSleeper(t, "27.1828")
+	check := m.ProcessCheck{Cmdline: `^sleep 27\.1828$`, MaxCPUPercent: Ptr(50.0)}
+
+	if err := check.Init(); err != nil {
+		t.Fatalf("Init, unexpected error: %v", err)
+	}
+
+	// First check has no cpu sample to compare against
+	output, err := check.Check()
+	if err != nil {
+		t.Fatalf("Check(first), unexpected error: %v", err)
+	}
+
+	if !strings.Contains(output, strconv.Itoa(sleeper.Process.Pid)) {
+		t.Errorf("Check(first), expected pid %d in output=%s", sleeper.Process.Pid, output)
+	}
+
+	if strings.Contains(output, "cpu=") {
+		t.Errorf("Check(first), expected no cpu value in output=%s", output)
+	}
+
+	output, err = check.Check()
+	if err != nil {
+		t.Fatalf("Check(second), unexpected error: %v", err)
+	}
+
+	if !strings.Contains(output, "cpu=") {
+		t.Errorf("Check(second), expected cpu value in output=%s", output)
+	}
+}
+
+func TestProcessCheckInit(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		check     m.ProcessCheck
+		expectErr bool
+		name      string
+	}{
+		{m.ProcessCheck{Name: "sleep"}, false, "Name"},
+		{m.ProcessCheck{}, true, "No selector"},
+		{m.ProcessCheck{Cmdline: "("}, true, "Invalid cmdline pattern"},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := c.check.Init()
+			hasErr := (err != nil)
+
+			if hasErr != c.expectErr {
+				t.Errorf("Init(%v), expected_error=%t actual=%v", c.name, c.expectErr, err)
+			}
+		})
+	}
+}
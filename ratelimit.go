@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidRateLimit indicates that a rate limit is not properly configured
+var ErrInvalidRateLimit = errors.New("Invalid rate limit configuration")
+
+// RateLimit is a config driven limit on how many alerts can be sent within a period
+type RateLimit struct {
+	Count     int    `hcl:"count"`
+	PeriodStr string `hcl:"period"`
+	Period    time.Duration
+
+	sent         []time.Time
+	suppressed   int
+	lastNotified time.Time
+}
+
+// Init parses the period of the RateLimit
+func (limit *RateLimit) Init() error {
+	var err error
+
+	limit.Period, err = time.ParseDuration(limit.PeriodStr)
+	if err != nil {
+		return fmt.Errorf("failed to parse rate limit period: %w", err)
+	}
+
+	return nil
+}
+
+// Validate checks that the RateLimit is properly configured and returns errors if not
+func (limit RateLimit) Validate() error {
+	var err error
+
+	if limit.Count <= 0 {
+		err = errors.Join(err, fmt.Errorf("%w: count must be greater than 0", ErrInvalidRateLimit))
+	}
+
+	if limit.Period <= 0 {
+		err = errors.Join(err, fmt.Errorf("%w: period must be greater than 0", ErrInvalidRateLimit))
+	}
+
+	return err
+}
+
+// IsLimited returns true if Count alerts have already been sent within the period before now
+func (limit *RateLimit) IsLimited(now time.Time) bool {
+	cutoff := now.Add(-limit.Period)
+	for len(limit.sent) > 0 && !limit.sent[0].After(cutoff) {
+		limit.sent = limit.sent[1:]
+	}
+
+	return len(limit.sent) >= limit.Count
+}
+
+// Record counts an alert sent at the given time against the limit
+func (limit *RateLimit) Record(now time.Time) {
+	limit.sent = append(limit.sent, now)
+}
+
+// Suppress counts alerts that were dropped because the limit was reached
+func (limit *RateLimit) Suppress(count int) {
+	limit.suppressed += count
+}
+
+// TakeSuppressed returns and resets the number of suppressed alerts. Suppressed alerts are
+// reported as soon as the limit is reached, and then at most once per period
+func (limit *RateLimit) TakeSuppressed(now time.Time) int {
+	if limit.suppressed == 0 || (!limit.lastNotified.IsZero() && now.Before(limit.lastNotified.Add(limit.Period))) {
+		return 0
+	}
+
+	suppressed := limit.suppressed
+	limit.suppressed = 0
+	limit.lastNotified = now
+
+	return suppressed
+}
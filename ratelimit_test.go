@@ -0,0 +1,91 @@
+package main_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	m "git.iamthefij.com/iamthefij/minitor-go/v2"
+)
+
+func TestRateLimitValidate(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		limit    m.RateLimit
+		expected error
+		name     string
+	}{
+		{m.RateLimit{Count: 10, PeriodStr: "1h"}, nil, "Valid"},
+		{m.RateLimit{Count: 0, PeriodStr: "1h"}, m.ErrInvalidRateLimit, "No count"},
+		{m.RateLimit{Count: 10, PeriodStr: "0s"}, m.ErrInvalidRateLimit, "No period"},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := c.limit.Init(); err != nil {
+				t.Fatalf("Init(%v), unexpected error: %v", c.name, err)
+			}
+
+			actual := c.limit.Validate()
+			hasErr := (actual != nil)
+			expectErr := (c.expected != nil)
+
+			if hasErr != expectErr || !errors.Is(actual, c.expected) {
+				t.Errorf("Validate(%v), expected=%v actual=%v", c.name, c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Parallel()
+
+	limit := m.RateLimit{Count: 2, PeriodStr: "1h"}
+	if err := limit.Init(); err != nil {
+		t.Fatalf("Init(), unexpected error: %v", err)
+	}
+
+	start := time.Now()
+
+	for i := 0; i < 2; i++ {
+		if limit.IsLimited(start) {
+			t.Fatalf("IsLimited(), expected alert %d to be allowed", i+1)
+		}
+
+		limit.Record(start)
+	}
+
+	if !limit.IsLimited(start.Add(59 * time.Minute)) {
+		t.Error("IsLimited(), expected limit to be reached within the period")
+	}
+
+	// The first suppressed alerts are reported right away
+	limit.Suppress(1)
+
+	if actual := limit.TakeSuppressed(start.Add(10 * time.Minute)); actual != 1 {
+		t.Errorf("TakeSuppressed(), expected 1 when the limit is first reached, got %d", actual)
+	}
+
+	limit.Suppress(2)
+
+	if actual := limit.TakeSuppressed(start.Add(69 * time.Minute)); actual != 0 {
+		t.Errorf("TakeSuppressed(), expected none before the period passed, got %d", actual)
+	}
+
+	if actual := limit.TakeSuppressed(start.Add(70 * time.Minute)); actual != 2 {
+		t.Errorf("TakeSuppressed(), expected 2, got %d", actual)
+	}
+
+	if actual := limit.TakeSuppressed(start.Add(2 * time.Hour)); actual != 0 {
+		t.Errorf("TakeSuppressed(), expected count to be reset, got %d", actual)
+	}
+
+	if limit.IsLimited(start.Add(time.Hour)) {
+		t.Error("IsLimited(), expected limit to reset after the period")
+	}
+}
@@ -0,0 +1,417 @@
+package main
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	errRedisProtocol = errors.New("redis protocol error")
+	errRedisReply    = errors.New("redis error reply")
+
+	redisInfoExpression = regexp.MustCompile(`^\s*(\S+)\s*(==|!=|>=|<=|>|<)\s*(.*?)\s*$`)
+)
+
+const defaultRedisTimeout = 5 * time.Second
+
+// RedisCheck is a config driven check of a Redis server's liveness, INFO fields and keys
+type RedisCheck struct {
+	Address       string      `hcl:"address"`
+	Username      string      `hcl:"username,optional"`
+	Password      string      `hcl:"password,optional"`
+	Database      int         `hcl:"database,optional"`
+	TLS           bool        `hcl:"tls,optional"`
+	TLSSkipVerify bool        `hcl:"tls_skip_verify,optional"`
+	TimeoutStr    *string     `hcl:"timeout,optional"`
+	Info          []string    `hcl:"info,optional"`
+	Keys          []*RedisKey `hcl:"key,block"`
+
+	Timeout    time.Duration
+	infoChecks []redisInfoCheck
+}
+
+// RedisKey asserts on the value of a single key in Redis
+type RedisKey struct {
+	Name      string  `hcl:"name,label"`
+	Equals    *string `hcl:"equals,optional"`
+	MaxAgeStr *string `hcl:"max_age,optional"`
+	MaxAge    time.Duration
+}
+
+type redisInfoCheck struct {
+	field    string
+	operator string
+	value    string
+}
+
+// Init parses durations and INFO expressions for the RedisCheck
+func (check *RedisCheck) Init() error {
+	check.Timeout = defaultRedisTimeout
+
+	if check.TimeoutStr != nil {
+		var err error
+
+		check.Timeout, err = time.ParseDuration(*check.TimeoutStr)
+		if err != nil {
+			return fmt.Errorf("failed to parse redis timeout duration: %w", err)
+		}
+	}
+
+	check.infoChecks = []redisInfoCheck{}
+
+	for _, expression := range check.Info {
+		matches := redisInfoExpression.FindStringSubmatch(expression)
+		if matches == nil {
+			return fmt.Errorf("%w: could not parse redis info expression %q", ErrInvalidMonitor, expression)
+		}
+
+		check.infoChecks = append(check.infoChecks, redisInfoCheck{
+			field:    matches[1],
+			operator: matches[2],
+			value:    matches[3],
+		})
+	}
+
+	for _, key := range check.Keys {
+		if key.MaxAgeStr != nil {
+			var err error
+
+			key.MaxAge, err = time.ParseDuration(*key.MaxAgeStr)
+			if err != nil {
+				return fmt.Errorf("failed to parse max_age duration for redis key %s: %w", key.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Check connects to Redis, runs all configured assertions and returns a report of the results
+func (check RedisCheck) Check() (string, error) {
+	conn, err := check.dial()
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	var output strings.Builder
+
+	reply, err := conn.Do("PING")
+	if err != nil {
+		return output.String(), err
+	}
+
+	fmt.Fprintf(&output, "PING: %v\n", reply)
+
+	if len(check.infoChecks) > 0 {
+		reply, err = conn.Do("INFO")
+		if err != nil {
+			return output.String(), err
+		}
+
+		info := parseRedisInfo(fmt.Sprint(reply))
+
+		for _, infoCheck := range check.infoChecks {
+			actual, ok := info[infoCheck.field]
+			if !ok {
+				fmt.Fprintf(&output, "INFO %s: missing\n", infoCheck.field)
+				err = errors.Join(err, fmt.Errorf("%w: redis info field %s is missing", ErrCheckFailed, infoCheck.field))
+
+				continue
+			}
+
+			if !infoCheck.compare(actual) {
+				fmt.Fprintf(&output, "INFO %s: %s, expected %s %s\n", infoCheck.field, actual, infoCheck.operator, infoCheck.value)
+				err = errors.Join(err, fmt.Errorf(
+					"%w: redis info %s=%s, expected %s %s",
+					ErrCheckFailed, infoCheck.field, actual, infoCheck.operator, infoCheck.value,
+				))
+
+				continue
+			}
+
+			fmt.Fprintf(&output, "INFO %s: %s\n", infoCheck.field, actual)
+		}
+	}
+
+	for _, key := range check.Keys {
+		keyErr := key.check(conn, &output)
+		err = errors.Join(err, keyErr)
+	}
+
+	return output.String(), err
+}
+
+func (key RedisKey) check(conn *redisConn, output io.Writer) error {
+	reply, err := conn.Do("GET", key.Name)
+	if err != nil {
+		return err
+	}
+
+	if reply == nil {
+		fmt.Fprintf(output, "KEY %s: missing\n", key.Name)
+
+		return fmt.Errorf("%w: redis key %s does not exist", ErrCheckFailed, key.Name)
+	}
+
+	value := fmt.Sprint(reply)
+	fmt.Fprintf(output, "KEY %s: %s\n", key.Name, value)
+
+	if key.Equals != nil && value != *key.Equals {
+		return fmt.Errorf("%w: redis key %s is %q, expected %q", ErrCheckFailed, key.Name, value, *key.Equals)
+	}
+
+	if key.MaxAgeStr != nil {
+		timestamp, err := parseTimestamp(value)
+		if err != nil {
+			return fmt.Errorf("%w: redis key %s is not a timestamp: %w", ErrCheckFailed, key.Name, err)
+		}
+
+		if age := time.Since(timestamp); age > key.MaxAge {
+			return fmt.Errorf("%w: redis key %s is %s old, expected at most %s", ErrCheckFailed, key.Name, age.Round(time.Second), key.MaxAge)
+		}
+	}
+
+	return nil
+}
+
+func (check RedisCheck) dial() (*redisConn, error) {
+	dialer := &net.Dialer{Timeout: check.Timeout}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+
+	if check.TLS {
+		host, _, _ := net.SplitHostPort(check.Address)
+		conn, err = tls.DialWithDialer(dialer, "tcp", check.Address, &tls.Config{
+			ServerName:         host,
+			InsecureSkipVerify: check.TLSSkipVerify, //nolint:gosec
+		})
+	} else {
+		conn, err = dialer.Dial("tcp", check.Address)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to redis at %s: %w", check.Address, err)
+	}
+
+	_ = conn.SetDeadline(time.Now().Add(check.Timeout))
+
+	redis := &redisConn{conn: conn, reader: bufio.NewReader(conn)}
+
+	if check.Password != "" {
+		args := []string{"AUTH", check.Password}
+		if check.Username != "" {
+			args = []string{"AUTH", check.Username, check.Password}
+		}
+
+		if _, err = redis.Do(args...); err != nil {
+			redis.Close()
+
+			return nil, err
+		}
+	}
+
+	if check.Database != 0 {
+		if _, err = redis.Do("SELECT", strconv.Itoa(check.Database)); err != nil {
+			redis.Close()
+
+			return nil, err
+		}
+	}
+
+	return redis, nil
+}
+
+func (infoCheck redisInfoCheck) compare(actual string) bool {
+	actualNum, actualErr := strconv.ParseFloat(actual, 64)
+	expectedNum, expectedErr := strconv.ParseFloat(infoCheck.value, 64)
+
+	// Compare numerically when possible, otherwise fall back to string comparison
+	if actualErr == nil && expectedErr == nil {
+		switch infoCheck.operator {
+		case "==":
+			return actualNum == expectedNum
+		case "!=":
+			return actualNum != expectedNum
+		case ">=":
+			return actualNum >= expectedNum
+		case "<=":
+			return actualNum <= expectedNum
+		case ">":
+			return actualNum > expectedNum
+		case "<":
+			return actualNum < expectedNum
+		}
+	}
+
+	switch infoCheck.operator {
+	case "==":
+		return actual == infoCheck.value
+	case "!=":
+		return actual != infoCheck.value
+	case ">=":
+		return actual >= infoCheck.value
+	case "<=":
+		return actual <= infoCheck.value
+	case ">":
+		return actual > infoCheck.value
+	case "<":
+		return actual < infoCheck.value
+	}
+
+	return false
+}
+
+// parseRedisInfo parses the field:value lines returned by the INFO command
+func parseRedisInfo(info string) map[string]string {
+	fields := map[string]string{}
+
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if field, value, ok := strings.Cut(line, ":"); ok {
+			fields[field] = value
+		}
+	}
+
+	return fields
+}
+
+// parseTimestamp parses either a unix timestamp in seconds or an RFC3339 string
+func parseTimestamp(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		whole := int64(seconds)
+
+		return time.Unix(whole, int64((seconds-float64(whole))*float64(time.Second))), nil
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return timestamp, fmt.Errorf("failed to parse timestamp %q: %w", value, err)
+	}
+
+	return timestamp, nil
+}
+
+// redisConn is a minimal client for the Redis serialization protocol
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// Do sends a command and returns the parsed reply
+func (redis *redisConn) Do(args ...string) (any, error) {
+	var command strings.Builder
+
+	fmt.Fprintf(&command, "*%d\r\n", len(args))
+
+	for _, arg := range args {
+		fmt.Fprintf(&command, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+
+	if _, err := io.WriteString(redis.conn, command.String()); err != nil {
+		return nil, fmt.Errorf("failed to send redis command %s: %w", args[0], err)
+	}
+
+	reply, err := redis.readReply()
+	if err != nil {
+		return nil, fmt.Errorf("redis command %s failed: %w", args[0], err)
+	}
+
+	return reply, nil
+}
+
+// Close closes the underlying connection
+func (redis *redisConn) Close() {
+	_ = redis.conn.Close()
+}
+
+func (redis *redisConn) readLine() (string, error) {
+	line, err := redis.reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read redis reply: %w", err)
+	}
+
+	return strings.TrimSuffix(line, "\r\n"), nil
+}
+
+func (redis *redisConn) readReply() (any, error) {
+	line, err := redis.readLine()
+	if err != nil {
+		return nil, err
+	}
+
+	if line == "" {
+		return nil, fmt.Errorf("%w: empty reply", errRedisProtocol)
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, fmt.Errorf("%w: %s", errRedisReply, line[1:])
+	case ':':
+		value, err := strconv.ParseInt(line[1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid integer %q", errRedisProtocol, line[1:])
+		}
+
+		return value, nil
+	case '$':
+		length, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid bulk length %q", errRedisProtocol, line[1:])
+		}
+
+		if length < 0 {
+			return nil, nil
+		}
+
+		data := make([]byte, length+2) //nolint:mnd
+		if _, err = io.ReadFull(redis.reader, data); err != nil {
+			return nil, fmt.Errorf("failed to read redis bulk reply: %w", err)
+		}
+
+		return string(data[:length]), nil
+	case '*':
+		length, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid array length %q", errRedisProtocol, line[1:])
+		}
+
+		if length < 0 {
+			return nil, nil
+		}
+
+		items := make([]any, 0, length)
+
+		for i := 0; i < length; i++ {
+			item, err := redis.readReply()
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, item)
+		}
+
+		return items, nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected reply %q", errRedisProtocol, line)
+	}
+}
@@ -0,0 +1,159 @@
+package main_test
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	m "git.iamthefij.com/iamthefij/minitor-go/v2"
+)
+
+// fakeRedis starts a minimal RESP server that replies to commands from the provided map
+func fakeRedis(t *testing.T, replies map[string]string) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start fake redis: %v", err)
+	}
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go serveFakeRedis(conn, replies)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func serveFakeRedis(conn net.Conn, replies map[string]string) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		count, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		args := []string{}
+
+		for i := 0; i < count; i++ {
+			_, _ = reader.ReadString('\n')
+			arg, _ := reader.ReadString('\n')
+			args = append(args, strings.TrimSpace(arg))
+		}
+
+		reply, ok := replies[strings.Join(args, " ")]
+		if !ok {
+			reply = "-ERR unknown command\r\n"
+		}
+
+		_, _ = io.WriteString(conn, reply)
+	}
+}
+
+func bulk(value string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+}
+
+func TestRedisCheck(t *testing.T) {
+	t.Parallel()
+
+	info := "# Replication\r\nrole:master\r\nconnected_slaves:2\r\n"
+	address := fakeRedis(t, map[string]string{
+		"AUTH secret":         "+OK\r\n",
+		"PING":                "+PONG\r\n",
+		"INFO":                bulk(info),
+		"GET heartbeat":       bulk(strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)),
+		"GET status":          bulk("ok"),
+		"GET missing":         "$-1\r\n",
+		"AUTH wrong":          "-WRONGPASS invalid password\r\n",
+		"SELECT 2":            "+OK\r\n",
+		"AUTH admin password": "+OK\r\n",
+	})
+
+	cases := []struct {
+		check     m.RedisCheck
+		expectErr bool
+		name      string
+	}{
+		{m.RedisCheck{Address: address}, false, "Ping"},
+		{m.RedisCheck{Address: address, Password: "secret"}, false, "Auth"},
+		{m.RedisCheck{Address: address, Username: "admin", Password: "password", Database: 2}, false, "ACL auth and select"},
+		{m.RedisCheck{Address: address, Password: "wrong"}, true, "Bad auth"},
+		{m.RedisCheck{Address: address, Info: []string{"role == master", "connected_slaves >= 1"}}, false, "Info passing"},
+		{m.RedisCheck{Address: address, Info: []string{"connected_slaves > 2"}}, true, "Info failing"},
+		{m.RedisCheck{Address: address, Info: []string{"unknown_field == 1"}}, true, "Info missing field"},
+		{m.RedisCheck{Address: address, Keys: []*m.RedisKey{{Name: "status", Equals: Ptr("ok")}}}, false, "Key equals"},
+		{m.RedisCheck{Address: address, Keys: []*m.RedisKey{{Name: "status", Equals: Ptr("bad")}}}, true, "Key not equals"},
+		{m.RedisCheck{Address: address, Keys: []*m.RedisKey{{Name: "missing"}}}, true, "Key missing"},
+		{m.RedisCheck{Address: address, Keys: []*m.RedisKey{{Name: "heartbeat", MaxAgeStr: Ptr("5m")}}}, false, "Key fresh"},
+		{m.RedisCheck{Address: address, Keys: []*m.RedisKey{{Name: "heartbeat", MaxAgeStr: Ptr("10s")}}}, true, "Key stale"},
+		{m.RedisCheck{Address: address, Keys: []*m.RedisKey{{Name: "status", MaxAgeStr: Ptr("10s")}}}, true, "Key not a timestamp"},
+		{m.RedisCheck{Address: "127.0.0.1:1", TimeoutStr: Ptr("1s")}, true, "Connection refused"},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := c.check.Init(); err != nil {
+				t.Fatalf("Init(%v), unexpected error: %v", c.name, err)
+			}
+
+			output, err := c.check.Check()
+			hasErr := (err != nil)
+
+			if hasErr != c.expectErr {
+				t.Errorf("Check(%v), expected_error=%t actual=%v output=%s", c.name, c.expectErr, err, output)
+			}
+		})
+	}
+}
+
+func TestRedisCheckInit(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		check     m.RedisCheck
+		expectErr bool
+		name      string
+	}{
+		{m.RedisCheck{Info: []string{"role == master"}}, false, "Valid info"},
+		{m.RedisCheck{Info: []string{"role"}}, true, "Invalid info"},
+		{m.RedisCheck{TimeoutStr: Ptr("soon")}, true, "Invalid timeout"},
+		{m.RedisCheck{Keys: []*m.RedisKey{{Name: "key", MaxAgeStr: Ptr("old")}}}, true, "Invalid max_age"},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := c.check.Init()
+			hasErr := (err != nil)
+
+			if hasErr != c.expectErr {
+				t.Errorf("Init(%v), expected_error=%t actual=%v", c.name, c.expectErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidSilence = errors.New("Invalid silence")
+	ErrUnknownSilence = errors.New("Unknown silence")
+)
+
+// silenceIDBytes is the number of random bytes used to generate a silence ID
+const silenceIDBytes = 8
+
+// Silence mutes alerts for matching monitors until it expires
+type Silence struct {
+	ID        string    `json:"id"`
+	Matcher   string    `json:"matcher"`
+	Author    string    `json:"author"`
+	Comment   string    `json:"comment"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewSilence creates a Silence starting now and lasting for the given duration
+func NewSilence(matcher string, duration time.Duration, author, comment string) Silence {
+	now := time.Now()
+
+	return Silence{
+		Matcher:   matcher,
+		Author:    author,
+		Comment:   comment,
+		CreatedAt: now,
+		ExpiresAt: now.Add(duration),
+	}
+}
+
+// Validate checks that the Silence is properly configured and returns errors if not
+func (silence Silence) Validate() error {
+	var err error
+
+	if silence.Matcher == "" {
+		err = errors.Join(err, fmt.Errorf("%w: matcher is required", ErrInvalidSilence))
+	} else if _, matchErr := path.Match(silence.Matcher, ""); matchErr != nil {
+		err = errors.Join(err, fmt.Errorf("%w: invalid matcher %q", ErrInvalidSilence, silence.Matcher))
+	}
+
+	if silence.Author == "" {
+		err = errors.Join(err, fmt.Errorf("%w: author is required", ErrInvalidSilence))
+	}
+
+	if !silence.ExpiresAt.After(silence.CreatedAt) {
+		err = errors.Join(err, fmt.Errorf("%w: duration must be positive", ErrInvalidSilence))
+	}
+
+	return err
+}
+
+// IsActive returns true if the Silence has not yet expired at the given time
+func (silence Silence) IsActive(now time.Time) bool {
+	return now.Before(silence.ExpiresAt)
+}
+
+// Matches returns true if the monitor name matches the name or glob pattern of the Silence
+func (silence Silence) Matches(monitorName string) bool {
+	matched, _ := path.Match(silence.Matcher, monitorName)
+
+	return matched
+}
+
+// SilenceStore holds all silences and persists them to a file, if one is set
+type SilenceStore struct {
+	lock     sync.RWMutex
+	filePath string
+	silences []Silence
+}
+
+// NewSilenceStore creates an empty SilenceStore that is not persisted
+func NewSilenceStore() *SilenceStore {
+	return &SilenceStore{}
+}
+
+// Load reads active silences from the given file and persists future changes to it
+func (store *SilenceStore) Load(filePath string) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	store.filePath = filePath
+	store.silences = nil
+
+	content, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read silences file: %w", err)
+	}
+
+	var silences []Silence
+	if err = json.Unmarshal(content, &silences); err != nil {
+		return fmt.Errorf("failed to parse silences file: %w", err)
+	}
+
+	now := time.Now()
+
+	for _, silence := range silences {
+		if silence.IsActive(now) {
+			store.silences = append(store.silences, silence)
+		}
+	}
+
+	return nil
+}
+
+// Add validates and stores a new Silence, returning it with an ID assigned
+func (store *SilenceStore) Add(silence Silence) (Silence, error) {
+	if err := silence.Validate(); err != nil {
+		return silence, err
+	}
+
+	id := make([]byte, silenceIDBytes)
+	if _, err := rand.Read(id); err != nil {
+		return silence, fmt.Errorf("failed to generate silence id: %w", err)
+	}
+
+	silence.ID = hex.EncodeToString(id)
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	store.silences = append(store.removeExpired(time.Now()), silence)
+
+	return silence, store.save()
+}
+
+// Expire removes the Silence with the given ID
+func (store *SilenceStore) Expire(id string) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	store.silences = store.removeExpired(time.Now())
+
+	for i, silence := range store.silences {
+		if silence.ID == id {
+			store.silences = append(store.silences[:i], store.silences[i+1:]...)
+
+			return store.save()
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnknownSilence, id)
+}
+
+// Active returns all silences that have not expired at the given time
+func (store *SilenceStore) Active(now time.Time) []Silence {
+	store.lock.RLock()
+	defer store.lock.RUnlock()
+
+	active := []Silence{}
+
+	for _, silence := range store.silences {
+		if silence.IsActive(now) {
+			active = append(active, silence)
+		}
+	}
+
+	return active
+}
+
+// Find returns the first active Silence matching the monitor name
+func (store *SilenceStore) Find(monitorName string, now time.Time) (Silence, bool) {
+	for _, silence := range store.Active(now) {
+		if silence.Matches(monitorName) {
+			return silence, true
+		}
+	}
+
+	return Silence{}, false
+}
+
+// removeExpired returns the stored silences without those expired at the given time. Caller must hold the lock
+func (store *SilenceStore) removeExpired(now time.Time) []Silence {
+	active := store.silences[:0]
+
+	for _, silence := range store.silences {
+		if silence.IsActive(now) {
+			active = append(active, silence)
+		}
+	}
+
+	return active
+}
+
+// save writes the stored silences to the file, if one is set. Caller must hold the lock
+func (store *SilenceStore) save() error {
+	if store.filePath == "" {
+		return nil
+	}
+
+	content, err := json.MarshalIndent(store.silences, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode silences: %w", err)
+	}
+
+	// Write to a temporary file first so that a crash never leaves a partial file
+	tmpPath := store.filePath + ".tmp"
+
+	if err = os.WriteFile(tmpPath, content, 0o600); err != nil {
+		return fmt.Errorf("failed to write silences file: %w", err)
+	}
+
+	if err = os.Rename(tmpPath, store.filePath); err != nil {
+		return fmt.Errorf("failed to write silences file: %w", err)
+	}
+
+	return nil
+}
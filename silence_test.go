@@ -0,0 +1,137 @@
+package main_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	m "git.iamthefij.com/iamthefij/minitor-go/v2"
+)
+
+func TestSilenceValidate(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		silence  m.Silence
+		expected error
+		name     string
+	}{
+		{m.NewSilence("web-*", time.Hour, "me", "upgrade"), nil, "Valid"},
+		{m.NewSilence("", time.Hour, "me", ""), m.ErrInvalidSilence, "No matcher"},
+		{m.NewSilence("[", time.Hour, "me", ""), m.ErrInvalidSilence, "Invalid matcher"},
+		{m.NewSilence("web", time.Hour, "", ""), m.ErrInvalidSilence, "No author"},
+		{m.NewSilence("web", -time.Hour, "me", ""), m.ErrInvalidSilence, "Negative duration"},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := c.silence.Validate()
+			hasErr := (actual != nil)
+			expectErr := (c.expected != nil)
+
+			if hasErr != expectErr || !errors.Is(actual, c.expected) {
+				t.Errorf("Validate(%v), expected=%v actual=%v", c.name, c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSilenceStore(t *testing.T) {
+	t.Parallel()
+
+	silencesPath := filepath.Join(t.TempDir(), "silences.json")
+	store := m.NewSilenceStore()
+
+	if err := store.Load(silencesPath); err != nil {
+		t.Fatalf("Load(missing file), unexpected error: %v", err)
+	}
+
+	web, err := store.Add(m.NewSilence("web-*", time.Hour, "me", "upgrade"))
+	if err != nil {
+		t.Fatalf("Add(web-*), unexpected error: %v", err)
+	}
+
+	if _, err = store.Add(m.NewSilence("db", time.Hour, "me", "migration")); err != nil {
+		t.Fatalf("Add(db), unexpected error: %v", err)
+	}
+
+	if _, err = store.Add(m.NewSilence("", time.Hour, "me", "")); !errors.Is(err, m.ErrInvalidSilence) {
+		t.Errorf("Add(invalid), expected=%v actual=%v", m.ErrInvalidSilence, err)
+	}
+
+	if silence, ok := store.Find("web-1", time.Now()); !ok || silence.ID != web.ID {
+		t.Errorf("Find(web-1), expected silence %s, got ok=%t silence=%v", web.ID, ok, silence)
+	}
+
+	if _, ok := store.Find("web-1", time.Now().Add(2*time.Hour)); ok {
+		t.Error("Find(web-1), expected no silence after expiry")
+	}
+
+	if _, ok := store.Find("cache", time.Now()); ok {
+		t.Error("Find(cache), expected no matching silence")
+	}
+
+	// Silences should be restored from the file
+	restored := m.NewSilenceStore()
+	if err = restored.Load(silencesPath); err != nil {
+		t.Fatalf("Load(), unexpected error: %v", err)
+	}
+
+	if actual := restored.Active(time.Now()); len(actual) != 2 {
+		t.Errorf("Load(), expected 2 silences, got %v", actual)
+	}
+
+	if err = restored.Expire(web.ID); err != nil {
+		t.Errorf("Expire(%s), unexpected error: %v", web.ID, err)
+	}
+
+	if err = restored.Expire(web.ID); !errors.Is(err, m.ErrUnknownSilence) {
+		t.Errorf("Expire(%s) twice, expected=%v actual=%v", web.ID, m.ErrUnknownSilence, err)
+	}
+
+	if err = store.Load(silencesPath); err != nil {
+		t.Fatalf("Load(), unexpected error: %v", err)
+	}
+
+	if _, ok := store.Find("web-1", time.Now()); ok {
+		t.Error("Load(), expected expired silence not to be persisted")
+	}
+}
+
+// TestSilenceStoreExpireUnknown tests that expiring an unknown silence does not corrupt the store
+// while it holds expired silences
+func TestSilenceStoreExpireUnknown(t *testing.T) {
+	t.Parallel()
+
+	store := m.NewSilenceStore()
+
+	for _, silence := range []m.Silence{
+		m.NewSilence("old", time.Millisecond, "me", "expires"),
+		m.NewSilence("web", time.Hour, "me", "upgrade"),
+		m.NewSilence("db", time.Hour, "me", "migration"),
+	} {
+		if _, err := store.Add(silence); err != nil {
+			t.Fatalf("Add(%s), unexpected error: %v", silence.Matcher, err)
+		}
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	if err := store.Expire("unknown"); !errors.Is(err, m.ErrUnknownSilence) {
+		t.Errorf("Expire(unknown), expected=%v actual=%v", m.ErrUnknownSilence, err)
+	}
+
+	actual := []string{}
+	for _, silence := range store.Active(time.Now()) {
+		actual = append(actual, silence.Matcher)
+	}
+
+	if expected := []string{"web", "db"}; !m.EqualSliceString(actual, expected) {
+		t.Errorf("Active(), expected=%v actual=%v", expected, actual)
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+// ErrInvalidSlack indicates that a slack alert is not properly configured
+var ErrInvalidSlack = errors.New("Invalid slack configuration")
+
+const (
+	// defaultSlackOutputLength is how much check output is included when no limit is configured
+	defaultSlackOutputLength = 1000
+
+	// slackMaxTextLength is the longest text Slack accepts in a section block
+	slackMaxTextLength = 3000
+	slackCodeFence     = "```"
+	// slackMaxOutputLength leaves room for the code fences around the check output
+	slackMaxOutputLength = slackMaxTextLength - 2*len(slackCodeFence)
+
+	slackColorUp       = "#2eb886"
+	slackColorDown     = "#a30200"
+	slackColorFlapping = "#daa038"
+)
+
+// slackEscaper escapes the characters that have special meaning in Slack mrkdwn
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// SlackAlert is a built in alert type that posts a message to a Slack or Mattermost incoming webhook
+type SlackAlert struct {
+	URL               string `hcl:"url"`
+	Channel           string `hcl:"channel,optional"`
+	Username          string `hcl:"username,optional"`
+	IconEmoji         string `hcl:"icon_emoji,optional"`
+	IconURL           string `hcl:"icon_url,optional"`
+	Title             string `hcl:"title,optional"`
+	Text              string `hcl:"text,optional"`
+	MaxOutputLength   int    `hcl:"max_output_length,optional"`
+	LegacyAttachments bool   `hcl:"legacy_attachments,optional"`
+
+	titleTemplate *template.Template
+	textTemplate  *template.Template
+}
+
+type slackMessage struct {
+	Text        string            `json:"text"`
+	Channel     string            `json:"channel,omitempty"`
+	Username    string            `json:"username,omitempty"`
+	IconEmoji   string            `json:"icon_emoji,omitempty"`
+	IconURL     string            `json:"icon_url,omitempty"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+type slackAttachment struct {
+	Color    string       `json:"color"`
+	Fallback string       `json:"fallback"`
+	Text     string       `json:"text,omitempty"`
+	Fields   []slackField `json:"fields,omitempty"`
+	Blocks   []slackBlock `json:"blocks,omitempty"`
+}
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+type slackBlock struct {
+	Type   string      `json:"type"`
+	Text   *slackText  `json:"text,omitempty"`
+	Fields []slackText `json:"fields,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Validate checks that the SlackAlert is properly configured and returns errors if not
+func (slack SlackAlert) Validate() error {
+	var err error
+
+	err = errors.Join(err, validateHTTPURL(ErrInvalidSlack, "url", slack.URL))
+
+	if slack.MaxOutputLength < 0 {
+		err = errors.Join(err, fmt.Errorf("%w: max_output_length must not be negative", ErrInvalidSlack))
+	}
+
+	if !slack.LegacyAttachments && slack.MaxOutputLength > slackMaxOutputLength {
+		err = errors.Join(err, fmt.Errorf(
+			"%w: max_output_length must be at most %d for block messages",
+			ErrInvalidSlack,
+			slackMaxOutputLength,
+		))
+	}
+
+	return err
+}
+
+// BuildTemplates compiles the title and text templates for the SlackAlert
+func (slack *SlackAlert) BuildTemplates(name string, funcs template.FuncMap) error {
+	var err error
+
+	title := slack.Title
+	if title == "" {
+		title = defaultNoticeTitle
+	}
+
+	slack.titleTemplate, err = template.New(name + "-title").Funcs(funcs).Parse(title)
+	if err != nil {
+		return err
+	}
+
+	slack.textTemplate, err = template.New(name + "-text").Funcs(funcs).Parse(slack.Text)
+
+	return err
+}
+
+// Send renders the message and posts it to the webhook url
+func (slack SlackAlert) Send(notice AlertNotice) (string, error) {
+	if slack.titleTemplate == nil || slack.textTemplate == nil {
+		return "", fmt.Errorf("No templates compiled for slack: %w", errNoTemplate)
+	}
+
+	var title, text strings.Builder
+
+	if err := slack.titleTemplate.Execute(&title, notice); err != nil {
+		return "", err
+	}
+
+	if err := slack.textTemplate.Execute(&text, notice); err != nil {
+		return "", err
+	}
+
+	attachment := slackAttachment{Color: noticeColor(notice), Fallback: title.String()}
+
+	if slack.LegacyAttachments {
+		attachment.Text = text.String()
+		attachment.Fields = slack.legacyFields(notice)
+	} else {
+		attachment.Blocks = slack.blocks(text.String(), notice)
+	}
+
+	return postJSON(slack.URL, nil, slackMessage{
+		Text:        title.String(),
+		Channel:     slack.Channel,
+		Username:    slack.Username,
+		IconEmoji:   slack.IconEmoji,
+		IconURL:     slack.IconURL,
+		Attachments: []slackAttachment{attachment},
+	})
+}
+
+// blocks builds Block Kit blocks for the text, notice details and check output
+func (slack SlackAlert) blocks(text string, notice AlertNotice) []slackBlock {
+	blocks := []slackBlock{}
+
+	if text != "" {
+		blocks = append(blocks, slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: text}})
+	}
+
+	blocks = append(blocks, slackBlock{Type: "section", Fields: []slackText{
+		{Type: "mrkdwn", Text: fmt.Sprintf("*Failure count*\n%d", notice.FailureCount)},
+		{Type: "mrkdwn", Text: "*Last success*\n" + formatLastSuccess(notice.LastSuccess)},
+	}})
+
+	if output := slack.output(notice); output != "" {
+		blocks = append(blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: slackCodeFence + escapeSlackOutput(output) + slackCodeFence},
+		})
+	}
+
+	return blocks
+}
+
+// legacyFields builds attachment fields for webhooks that do not support Block Kit, such as Mattermost
+func (slack SlackAlert) legacyFields(notice AlertNotice) []slackField {
+	fields := []slackField{
+		{Title: "Failure count", Value: fmt.Sprint(notice.FailureCount), Short: true},
+		{Title: "Last success", Value: formatLastSuccess(notice.LastSuccess), Short: true},
+	}
+
+	if output := slack.output(notice); output != "" {
+		fields = append(fields, slackField{Title: "Output", Value: "```\n" + output + "\n```"})
+	}
+
+	return fields
+}
+
+// output returns the check output truncated to the configured length
+func (slack SlackAlert) output(notice AlertNotice) string {
+	maxLength := slack.MaxOutputLength
+	if maxLength == 0 {
+		maxLength = defaultSlackOutputLength
+	}
+
+	return truncateText(strings.TrimSpace(notice.LastCheckOutput), maxLength)
+}
+
+// escapeSlackOutput escapes the output for mrkdwn and truncates it so the escaped text still fits in
+// a section block. Whole characters are dropped so escape sequences are never split
+func escapeSlackOutput(output string) string {
+	escaped := slackEscaper.Replace(output)
+	if utf8.RuneCountInString(escaped) <= slackMaxOutputLength {
+		return escaped
+	}
+
+	var (
+		truncated strings.Builder
+		length    int
+	)
+
+	for _, char := range output {
+		escapedChar := slackEscaper.Replace(string(char))
+
+		length += utf8.RuneCountInString(escapedChar)
+		if length > slackMaxOutputLength-1 {
+			break
+		}
+
+		truncated.WriteString(escapedChar)
+	}
+
+	return truncated.String() + "…"
+}
+
+// noticeColor returns a hex color representing the state of the notice
+func noticeColor(notice AlertNotice) string {
+	switch {
+	case notice.IsFlapping:
+		return slackColorFlapping
+	case notice.IsUp:
+		return slackColorUp
+	default:
+		return slackColorDown
+	}
+}
@@ -0,0 +1,190 @@
+package main_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	m "git.iamthefij.com/iamthefij/minitor-go/v2"
+)
+
+func TestSlackAlertValidate(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		slack    m.SlackAlert
+		expected error
+		name     string
+	}{
+		{m.SlackAlert{URL: "https://hooks.slack.com/services/T/B/X"}, nil, "Valid"},
+		{m.SlackAlert{}, m.ErrInvalidSlack, "No url"},
+		{m.SlackAlert{URL: "hooks.slack.com/services/T/B/X"}, m.ErrInvalidSlack, "Relative url"},
+		{m.SlackAlert{URL: "https://hooks.slack.com/services/T/B/X", MaxOutputLength: -1}, m.ErrInvalidSlack, "Negative output length"},
+		{m.SlackAlert{URL: "https://hooks.slack.com/services/T/B/X", MaxOutputLength: 2994}, nil, "Max output length"},
+		{m.SlackAlert{URL: "https://hooks.slack.com/services/T/B/X", MaxOutputLength: 2995}, m.ErrInvalidSlack, "Output length too long"},
+		{
+			m.SlackAlert{URL: "https://hooks.slack.com/services/T/B/X", LegacyAttachments: true, MaxOutputLength: 5000},
+			nil,
+			"Legacy long output length",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := c.slack.Validate()
+			hasErr := (actual != nil)
+			expectErr := (c.expected != nil)
+
+			if hasErr != expectErr || !errors.Is(actual, c.expected) {
+				t.Errorf("Validate(%v), expected=%v actual=%v", c.name, c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSlackAlertSend(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newWebhookServer(t, http.StatusOK)
+	lastSuccess := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	type slackPayload struct {
+		Text        string `json:"text"`
+		Channel     string `json:"channel"`
+		Attachments []struct {
+			Color  string `json:"color"`
+			Text   string `json:"text"`
+			Fields []struct {
+				Title string `json:"title"`
+				Value string `json:"value"`
+			} `json:"fields"`
+			Blocks []struct {
+				Type string `json:"type"`
+				Text *struct {
+					Text string `json:"text"`
+				} `json:"text"`
+				Fields []struct {
+					Text string `json:"text"`
+				} `json:"fields"`
+			} `json:"blocks"`
+		} `json:"attachments"`
+	}
+
+	cases := []struct {
+		slack         m.SlackAlert
+		notice        m.AlertNotice
+		expectedText  string
+		expectedColor string
+		expected      []string
+		name          string
+	}{
+		{
+			m.SlackAlert{Channel: "#ops"},
+			m.AlertNotice{MonitorName: "web", FailureCount: 3, LastSuccess: lastSuccess, LastCheckOutput: "a < b & c"},
+			"web is down",
+			"#a30200",
+			[]string{"*Failure count*\n3", "*Last success*\n" + lastSuccess.Format(time.RFC1123), "```a &lt; b &amp; c```"},
+			"Blocks down",
+		},
+		{
+			m.SlackAlert{Title: "Recovered {{.MonitorName}}", Text: "All good", MaxOutputLength: 5},
+			m.AlertNotice{MonitorName: "web", IsUp: true, LastCheckOutput: "0123456789"},
+			"Recovered web",
+			"#2eb886",
+			[]string{"All good", "*Last success*\nNever", "```0123…```"},
+			"Blocks up truncated",
+		},
+		{
+			m.SlackAlert{MaxOutputLength: 2994},
+			m.AlertNotice{MonitorName: "web", LastCheckOutput: strings.Repeat("<", 2994)},
+			"web is down",
+			"#a30200",
+			[]string{"```" + strings.Repeat("&lt;", 748) + "…```"},
+			"Blocks escaped output truncated",
+		},
+		{
+			m.SlackAlert{LegacyAttachments: true, Text: "Details"},
+			m.AlertNotice{MonitorName: "web", FailureCount: 1, LastCheckOutput: "a < b"},
+			"web is down",
+			"#a30200",
+			[]string{"Details", "Failure count=1", "Output=```\na < b\n```"},
+			"Legacy attachments",
+		},
+	}
+
+	for _, c := range cases {
+		alert := m.Alert{Name: "slack", Slack: &c.slack}
+		alert.Slack.URL = server.URL
+
+		if err := alert.BuildTemplates(); err != nil {
+			t.Fatalf("BuildTemplates(%v), unexpected error: %v", c.name, err)
+		}
+
+		if _, err := alert.Send(c.notice); err != nil {
+			t.Fatalf("Send(%v), unexpected error: %v", c.name, err)
+		}
+
+		request := <-requests
+
+		var payload slackPayload
+		if err := json.Unmarshal([]byte(request.body), &payload); err != nil {
+			t.Fatalf("Send(%v), invalid json body %q: %v", c.name, request.body, err)
+		}
+
+		if payload.Text != c.expectedText || len(payload.Attachments) != 1 || payload.Attachments[0].Color != c.expectedColor {
+			t.Errorf("Send(%v), unexpected payload %q", c.name, request.body)
+
+			continue
+		}
+
+		attachment := payload.Attachments[0]
+		rendered := []string{attachment.Text}
+
+		for _, field := range attachment.Fields {
+			rendered = append(rendered, field.Title+"="+field.Value)
+		}
+
+		for _, block := range attachment.Blocks {
+			if block.Text != nil && utf8.RuneCountInString(block.Text.Text) > 3000 {
+				t.Errorf("Send(%v), block text longer than 3000 characters: %d", c.name, utf8.RuneCountInString(block.Text.Text))
+			}
+
+			if block.Text != nil {
+				rendered = append(rendered, block.Text.Text)
+			}
+
+			for _, field := range block.Fields {
+				rendered = append(rendered, field.Text)
+			}
+		}
+
+		for _, expected := range c.expected {
+			if !strings.Contains(strings.Join(rendered, "\n"), expected) {
+				t.Errorf("Send(%v), expected %q in %q", c.name, expected, rendered)
+			}
+		}
+	}
+}
+
+func TestSlackAlertSendFailed(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newWebhookServer(t, http.StatusNotFound)
+	alert := m.Alert{Name: "slack", Slack: &m.SlackAlert{URL: server.URL}}
+
+	if err := alert.BuildTemplates(); err != nil {
+		t.Fatalf("BuildTemplates(), unexpected error: %v", err)
+	}
+
+	if _, err := alert.Send(m.AlertNotice{MonitorName: "web"}); !errors.Is(err, m.ErrAlertFailed) {
+		t.Errorf("Send(), expected=%v actual=%v", m.ErrAlertFailed, err)
+	}
+}
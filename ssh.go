@@ -0,0 +1,186 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"git.iamthefij.com/iamthefij/slog"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	defaultSSHPort    = 22
+	defaultSSHTimeout = 10 * time.Second
+)
+
+// sshClients is the pool of open connections shared between all monitors
+var sshClients = &sshClientPool{clients: map[string]*ssh.Client{}}
+
+// SSHConfig configures a Monitor to run its command on a remote host
+type SSHConfig struct {
+	Host       string  `hcl:"host"`
+	Port       int     `hcl:"port,optional"`
+	User       string  `hcl:"user"`
+	KeyPath    string  `hcl:"key_path"`
+	KnownHosts string  `hcl:"known_hosts,optional"`
+	TimeoutStr *string `hcl:"timeout,optional"`
+
+	Timeout      time.Duration
+	clientConfig *ssh.ClientConfig
+}
+
+// Init applies defaults and loads the key and known hosts files for the SSHConfig
+func (sshConfig *SSHConfig) Init() error {
+	if sshConfig.Port == 0 {
+		sshConfig.Port = defaultSSHPort
+	}
+
+	sshConfig.Timeout = defaultSSHTimeout
+
+	if sshConfig.TimeoutStr != nil {
+		var err error
+
+		sshConfig.Timeout, err = time.ParseDuration(*sshConfig.TimeoutStr)
+		if err != nil {
+			return fmt.Errorf("failed to parse ssh timeout duration: %w", err)
+		}
+	}
+
+	if sshConfig.KnownHosts == "" {
+		homeDir, _ := os.UserHomeDir()
+		sshConfig.KnownHosts = filepath.Join(homeDir, ".ssh", "known_hosts")
+	}
+
+	key, err := os.ReadFile(sshConfig.KeyPath)
+	if err != nil {
+		return fmt.Errorf("failed to read ssh key: %w", err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("failed to parse ssh key %s: %w", sshConfig.KeyPath, err)
+	}
+
+	hostKeyCallback, err := knownhosts.New(sshConfig.KnownHosts)
+	if err != nil {
+		return fmt.Errorf("failed to load ssh known_hosts: %w", err)
+	}
+
+	sshConfig.clientConfig = &ssh.ClientConfig{
+		User:            sshConfig.User,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         sshConfig.Timeout,
+	}
+
+	return nil
+}
+
+// Address returns the host and port to connect to
+func (sshConfig SSHConfig) Address() string {
+	return net.JoinHostPort(sshConfig.Host, strconv.Itoa(sshConfig.Port))
+}
+
+// Run executes a command on the remote host and returns the combined output.
+// A non-zero exit status from the remote command is returned as an error, just
+// as it would be when running a command locally.
+func (sshConfig SSHConfig) Run(command string) (string, error) {
+	if sshConfig.clientConfig == nil {
+		return "", fmt.Errorf("%w: ssh connection to %s is not initialized", ErrInvalidMonitor, sshConfig.Address())
+	}
+
+	client, err := sshClients.get(sshConfig)
+	if err != nil {
+		return "", err
+	}
+
+	session, err := client.NewSession()
+	if err != nil {
+		// The pooled connection may have gone stale, so reconnect once
+		slog.Debugf("Reconnecting to %s after failing to open session: %v", sshConfig.Address(), err)
+		sshClients.drop(sshConfig, client)
+
+		client, err = sshClients.get(sshConfig)
+		if err != nil {
+			return "", err
+		}
+
+		session, err = client.NewSession()
+		if err != nil {
+			return "", fmt.Errorf("failed to open ssh session on %s: %w", sshConfig.Address(), err)
+		}
+	}
+	defer session.Close()
+
+	output, err := session.CombinedOutput(command)
+
+	var exitErr *ssh.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		// Anything other than a remote exit status means the connection is unusable
+		sshClients.drop(sshConfig, client)
+	}
+
+	return string(output), err //nolint:wrapcheck
+}
+
+// ShellQuote joins a command into a single string that can be passed to a remote shell
+func ShellQuote(command []string) string {
+	quoted := make([]string, 0, len(command))
+
+	for _, part := range command {
+		quoted = append(quoted, "'"+strings.ReplaceAll(part, "'", `'\''`)+"'")
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+// sshClientPool keeps connections open so they can be reused between checks
+type sshClientPool struct {
+	lock    sync.Mutex
+	clients map[string]*ssh.Client
+}
+
+func (pool *sshClientPool) key(sshConfig SSHConfig) string {
+	return sshConfig.User + "@" + sshConfig.Address() + " " + sshConfig.KeyPath
+}
+
+func (pool *sshClientPool) get(sshConfig SSHConfig) (*ssh.Client, error) {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	key := pool.key(sshConfig)
+	if client, ok := pool.clients[key]; ok {
+		return client, nil
+	}
+
+	slog.Debugf("Opening ssh connection to %s", key)
+
+	client, err := ssh.Dial("tcp", sshConfig.Address(), sshConfig.clientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s over ssh: %w", key, err)
+	}
+
+	pool.clients[key] = client
+
+	return client, nil
+}
+
+func (pool *sshClientPool) drop(sshConfig SSHConfig, client *ssh.Client) {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	key := pool.key(sshConfig)
+	if pool.clients[key] == client {
+		delete(pool.clients, key)
+	}
+
+	_ = client.Close()
+}
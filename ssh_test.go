@@ -0,0 +1,229 @@
+package main_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	m "git.iamthefij.com/iamthefij/minitor-go/v2"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// fakeSSHServer starts an ssh server that runs exec requests locally. It returns
+// the monitor ssh config to connect to it and a counter of accepted connections.
+func fakeSSHServer(t *testing.T) (m.SSHConfig, *int32) {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	_, hostPrivate, _ := ed25519.GenerateKey(rand.Reader)
+	hostSigner, _ := ssh.NewSignerFromKey(hostPrivate)
+
+	clientPublic, clientPrivate, _ := ed25519.GenerateKey(rand.Reader)
+	clientSSHPublic, _ := ssh.NewPublicKey(clientPublic)
+
+	pemBlock, err := ssh.MarshalPrivateKey(clientPrivate, "")
+	if err != nil {
+		t.Fatalf("failed to marshal client key: %v", err)
+	}
+
+	keyPath := filepath.Join(dir, "id_ed25519")
+	if err = os.WriteFile(keyPath, pem.EncodeToMemory(pemBlock), 0o600); err != nil {
+		t.Fatalf("failed to write client key: %v", err)
+	}
+
+	serverConfig := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) == string(clientSSHPublic.Marshal()) {
+				return &ssh.Permissions{}, nil
+			}
+
+			return nil, errors.New("unknown key")
+		},
+	}
+	serverConfig.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start fake ssh server: %v", err)
+	}
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	knownHostsPath := filepath.Join(dir, "known_hosts")
+	knownHostsLine := knownhosts.Line([]string{knownhosts.Normalize(listener.Addr().String())}, hostSigner.PublicKey())
+
+	if err = os.WriteFile(knownHostsPath, []byte(knownHostsLine+"\n"), 0o600); err != nil {
+		t.Fatalf("failed to write known_hosts: %v", err)
+	}
+
+	connections := new(int32)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			atomic.AddInt32(connections, 1)
+
+			go serveFakeSSH(conn, serverConfig)
+		}
+	}()
+
+	host, portStr, _ := net.SplitHostPort(listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	return m.SSHConfig{
+		Host:       host,
+		Port:       port,
+		User:       "minitor",
+		KeyPath:    keyPath,
+		KnownHosts: knownHostsPath,
+	}, connections
+}
+
+func serveFakeSSH(conn net.Conn, config *ssh.ServerConfig) {
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+
+		go func() {
+			defer channel.Close()
+
+			for req := range channelRequests {
+				if req.Type != "exec" {
+					_ = req.Reply(false, nil)
+
+					continue
+				}
+
+				_ = req.Reply(true, nil)
+
+				var payload struct{ Command string }
+				_ = ssh.Unmarshal(req.Payload, &payload)
+
+				output, err := exec.Command("sh", "-c", payload.Command).CombinedOutput()
+				_, _ = channel.Write(output)
+
+				exitCode := 0
+
+				var exitErr *exec.ExitError
+				if errors.As(err, &exitErr) {
+					exitCode = exitErr.ExitCode()
+				}
+
+				status := make([]byte, 4)
+				binary.BigEndian.PutUint32(status, uint32(exitCode))
+				_, _ = channel.SendRequest("exit-status", false, status)
+
+				return
+			}
+		}()
+	}
+}
+
+func TestSSHMonitorCheck(t *testing.T) {
+	t.Parallel()
+
+	sshConfig, connections := fakeSSHServer(t)
+
+	cases := []struct {
+		monitor    m.Monitor
+		isSuccess  bool
+		lastOutput string
+		name       string
+	}{
+		{m.Monitor{Command: []string{"echo", "it's remote"}}, true, "it's remote\n", "Command"},
+		{m.Monitor{ShellCommand: "echo remote shell"}, true, "remote shell\n", "Shell command"},
+		{m.Monitor{ShellCommand: "echo failed; exit 3"}, false, "failed\n", "Failed shell command"},
+		{m.Monitor{Command: []string{"false"}}, false, "", "Failed command"},
+	}
+
+	for _, c := range cases {
+		sshConfig := sshConfig
+		c.monitor.Name = c.name
+		c.monitor.AlertAfter = 1
+		c.monitor.SSH = &sshConfig
+
+		if err := c.monitor.SSH.Init(); err != nil {
+			t.Fatalf("Init(%v), unexpected error: %v", c.name, err)
+		}
+
+		isSuccess, _ := c.monitor.Check()
+		if isSuccess != c.isSuccess {
+			t.Errorf("Check(%v) (success), expected=%t actual=%t", c.name, c.isSuccess, isSuccess)
+		}
+
+		if lastOutput := c.monitor.LastOutput(); lastOutput != c.lastOutput {
+			t.Errorf("Check(%v) (output), expected=%q actual=%q", c.name, c.lastOutput, lastOutput)
+		}
+	}
+
+	// All checks against the same host should have shared a single connection
+	if actual := atomic.LoadInt32(connections); actual != 1 {
+		t.Errorf("Check(connection reuse), expected=1 actual=%d", actual)
+	}
+}
+
+func TestSSHConfigInit(t *testing.T) {
+	t.Parallel()
+
+	sshConfig, _ := fakeSSHServer(t)
+
+	cases := []struct {
+		sshConfig m.SSHConfig
+		expectErr bool
+		name      string
+	}{
+		{sshConfig, false, "Valid"},
+		{m.SSHConfig{Host: "localhost", User: "test", KeyPath: "./does-not-exist", KnownHosts: sshConfig.KnownHosts}, true, "Missing key"},
+		{m.SSHConfig{Host: "localhost", User: "test", KeyPath: sshConfig.KeyPath, KnownHosts: "./does-not-exist"}, true, "Missing known_hosts"},
+		{m.SSHConfig{Host: "localhost", User: "test", KeyPath: sshConfig.KnownHosts, KnownHosts: sshConfig.KnownHosts}, true, "Invalid key"},
+		{m.SSHConfig{Host: "localhost", User: "test", KeyPath: sshConfig.KeyPath, TimeoutStr: Ptr("soon")}, true, "Invalid timeout"},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := c.sshConfig.Init()
+			hasErr := (err != nil)
+
+			if hasErr != c.expectErr {
+				t.Errorf("Init(%v), expected_error=%t actual=%v", c.name, c.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	t.Parallel()
+
+	expected := `'echo' 'it'\''s' '$HOME'`
+	if actual := m.ShellQuote([]string{"echo", "it's", "$HOME"}); actual != expected {
+		t.Errorf("ShellQuote, expected=%v actual=%v", expected, actual)
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// ErrInvalidTelegram indicates that a telegram alert is not properly configured
+var ErrInvalidTelegram = errors.New("Invalid telegram configuration")
+
+const (
+	defaultTelegramURL = "https://api.telegram.org"
+
+	telegramParseModeMarkdownV2 = "MarkdownV2"
+	telegramParseModeHTML       = "HTML"
+
+	// telegramMaxOutputLength limits check output so messages stay within the Telegram message limit
+	telegramMaxOutputLength = 3000
+	// telegramEscapeFunc is the template function added to every action to escape rendered values
+	telegramEscapeFunc = "telegramEscape"
+
+	defaultTelegramMarkdownV2Message = "*{{.MonitorName}} is {{if .IsUp}}up{{else}}down{{end}}*\n" +
+		"Failures: {{.FailureCount}}\nLast success: {{RFC1123 .LastSuccess}}" +
+		"{{if .LastCheckOutput}}\n```\n{{.LastCheckOutput}}\n```{{end}}"
+	defaultTelegramHTMLMessage = "<b>{{.MonitorName}} is {{if .IsUp}}up{{else}}down{{end}}</b>\n" +
+		"Failures: {{.FailureCount}}\nLast success: {{RFC1123 .LastSuccess}}" +
+		"{{if .LastCheckOutput}}\n<pre>{{.LastCheckOutput}}</pre>{{end}}"
+)
+
+// telegramEscapers escape the characters that are special in each parse mode
+var telegramEscapers = map[string]*strings.Replacer{
+	telegramParseModeMarkdownV2: strings.NewReplacer(
+		`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`, "~", `\~`, "`", "\\`",
+		">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`, "|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+	),
+	telegramParseModeHTML: strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;"),
+}
+
+// TelegramAlert is a built in alert type that sends a message to Telegram chats through a bot
+type TelegramAlert struct {
+	URL       string   `hcl:"url,optional"`
+	BotToken  string   `hcl:"bot_token"`
+	ChatIDs   []string `hcl:"chat_ids"`
+	ParseMode string   `hcl:"parse_mode,optional"`
+	Message   string   `hcl:"message,optional"`
+
+	messageTemplate *template.Template
+}
+
+type telegramMessage struct {
+	ChatID                string `json:"chat_id"`
+	Text                  string `json:"text"`
+	ParseMode             string `json:"parse_mode,omitempty"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview"`
+}
+
+// Validate checks that the TelegramAlert is properly configured and returns errors if not
+func (telegram TelegramAlert) Validate() error {
+	var err error
+
+	err = errors.Join(err, validateHTTPURL(ErrInvalidTelegram, "url", telegram.url()))
+
+	if telegram.BotToken == "" {
+		err = errors.Join(err, fmt.Errorf("%w: bot_token is required", ErrInvalidTelegram))
+	}
+
+	if len(telegram.ChatIDs) == 0 {
+		err = errors.Join(err, fmt.Errorf("%w: at least one chat id is required", ErrInvalidTelegram))
+	}
+
+	if _, ok := telegramEscapers[telegram.parseMode()]; !ok && telegram.ParseMode != "" {
+		err = errors.Join(err, fmt.Errorf(
+			"%w: parse_mode must be one of %s or %s",
+			ErrInvalidTelegram,
+			telegramParseModeMarkdownV2,
+			telegramParseModeHTML,
+		))
+	}
+
+	return err
+}
+
+// BuildTemplates compiles the message template for the TelegramAlert, escaping every rendered
+// value for the parse mode
+func (telegram *TelegramAlert) BuildTemplates(name string, funcs template.FuncMap) error {
+	parseMode := telegram.parseMode()
+	escaper, shouldEscape := telegramEscapers[parseMode]
+
+	message := telegram.Message
+
+	if message == "" {
+		switch parseMode {
+		case telegramParseModeMarkdownV2:
+			message = defaultTelegramMarkdownV2Message
+		case telegramParseModeHTML:
+			message = defaultTelegramHTMLMessage
+		default:
+			message = defaultNoticeMessage
+		}
+	}
+
+	tmpl := template.New(name + "-message").Funcs(funcs)
+
+	if shouldEscape {
+		tmpl = tmpl.Funcs(template.FuncMap{
+			telegramEscapeFunc: func(value any) string { return escaper.Replace(fmt.Sprint(value)) },
+		})
+	}
+
+	tmpl, err := tmpl.Parse(message)
+	if err != nil {
+		return err
+	}
+
+	if shouldEscape {
+		for _, definedTemplate := range tmpl.Templates() {
+			if definedTemplate.Tree != nil {
+				escapeActions(definedTemplate.Root, telegramEscapeFunc)
+			}
+		}
+	}
+
+	telegram.messageTemplate = tmpl
+
+	return nil
+}
+
+// Send renders the message and sends it to each chat
+func (telegram TelegramAlert) Send(notice AlertNotice) (string, error) {
+	if telegram.messageTemplate == nil {
+		return "", fmt.Errorf("No templates compiled for telegram: %w", errNoTemplate)
+	}
+
+	notice.LastCheckOutput = truncateText(strings.TrimSpace(notice.LastCheckOutput), telegramMaxOutputLength)
+
+	text, err := renderTemplate(telegram.messageTemplate, notice)
+	if err != nil {
+		return "", err
+	}
+
+	sendURL := fmt.Sprintf("%s/bot%s/sendMessage", strings.TrimSuffix(telegram.url(), "/"), telegram.BotToken)
+	outputs := []string{}
+
+	for _, chatID := range telegram.ChatIDs {
+		output, sendErr := postJSON(sendURL, nil, telegramMessage{
+			ChatID:                chatID,
+			Text:                  text,
+			ParseMode:             telegram.parseMode(),
+			DisableWebPagePreview: true,
+		})
+		outputs = append(outputs, output)
+
+		if sendErr != nil {
+			// Errors from the http client include the url, which contains the secret bot token
+			err = errors.Join(err, fmt.Errorf(
+				"%w: failed to send to chat %s: %s",
+				ErrAlertFailed,
+				chatID,
+				strings.ReplaceAll(sendErr.Error(), telegram.BotToken, "<bot_token>"),
+			))
+		}
+	}
+
+	return strings.Join(outputs, "\n"), err
+}
+
+func (telegram TelegramAlert) url() string {
+	if telegram.URL == "" {
+		return defaultTelegramURL
+	}
+
+	return telegram.URL
+}
+
+// parseMode returns the parse mode with the casing expected by Telegram
+func (telegram TelegramAlert) parseMode() string {
+	for parseMode := range telegramEscapers {
+		if strings.EqualFold(parseMode, telegram.ParseMode) {
+			return parseMode
+		}
+	}
+
+	return telegram.ParseMode
+}
+
+// escapeActions appends the named function to the pipeline of every action that prints a value,
+// so that all rendered values are escaped while the literal text of the template is not
+func escapeActions(node parse.Node, funcName string) {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return
+		}
+
+		for _, child := range node.Nodes {
+			escapeActions(child, funcName)
+		}
+	case *parse.ActionNode:
+		// Actions that declare variables do not print anything
+		if len(node.Pipe.Decl) > 0 {
+			return
+		}
+
+		node.Pipe.Cmds = append(node.Pipe.Cmds, &parse.CommandNode{
+			NodeType: parse.NodeCommand,
+			Pos:      node.Pos,
+			Args:     []parse.Node{parse.NewIdentifier(funcName).SetTree(nil).SetPos(node.Pos)},
+		})
+	case *parse.IfNode:
+		escapeActions(node.List, funcName)
+		escapeActions(node.ElseList, funcName)
+	case *parse.RangeNode:
+		escapeActions(node.List, funcName)
+		escapeActions(node.ElseList, funcName)
+	case *parse.WithNode:
+		escapeActions(node.List, funcName)
+		escapeActions(node.ElseList, funcName)
+	}
+}
@@ -0,0 +1,148 @@
+package main_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	m "git.iamthefij.com/iamthefij/minitor-go/v2"
+)
+
+func TestTelegramAlertValidate(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		telegram m.TelegramAlert
+		expected error
+		name     string
+	}{
+		{m.TelegramAlert{BotToken: "123:abc", ChatIDs: []string{"42"}}, nil, "Valid"},
+		{m.TelegramAlert{BotToken: "123:abc", ChatIDs: []string{"42", "@channel"}, ParseMode: "markdownv2"}, nil, "Valid markdown"},
+		{m.TelegramAlert{BotToken: "123:abc", ChatIDs: []string{"42"}, ParseMode: "HTML"}, nil, "Valid html"},
+		{m.TelegramAlert{ChatIDs: []string{"42"}}, m.ErrInvalidTelegram, "No bot token"},
+		{m.TelegramAlert{BotToken: "123:abc"}, m.ErrInvalidTelegram, "No chat ids"},
+		{m.TelegramAlert{BotToken: "123:abc", ChatIDs: []string{"42"}, ParseMode: "Markdown"}, m.ErrInvalidTelegram, "Legacy markdown"},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := c.telegram.Validate()
+			hasErr := (actual != nil)
+			expectErr := (c.expected != nil)
+
+			if hasErr != expectErr || !errors.Is(actual, c.expected) {
+				t.Errorf("Validate(%v), expected=%v actual=%v", c.name, c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestTelegramAlertSend(t *testing.T) {
+	t.Parallel()
+
+	type telegramRequest struct {
+		path    string
+		message struct {
+			ChatID    string `json:"chat_id"`
+			Text      string `json:"text"`
+			ParseMode string `json:"parse_mode"`
+		}
+	}
+
+	requests := make(chan telegramRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := telegramRequest{path: r.URL.Path}
+
+		_ = json.NewDecoder(r.Body).Decode(&request.message)
+		requests <- request
+
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}))
+
+	t.Cleanup(server.Close)
+
+	notice := m.AlertNotice{MonitorName: "web_app", FailureCount: 2, LastCheckOutput: "a*b <c> & [d](e)!"}
+
+	cases := []struct {
+		telegram m.TelegramAlert
+		expected string
+		name     string
+	}{
+		{
+			m.TelegramAlert{Message: "{{.MonitorName}}: {{.LastCheckOutput}}"},
+			"web_app: a*b <c> & [d](e)!",
+			"No parse mode",
+		},
+		{
+			m.TelegramAlert{ParseMode: "MarkdownV2", Message: "*{{.MonitorName}}* {{if not .IsUp}}_{{.LastCheckOutput}}_{{end}}"},
+			`*web\_app* _a\*b <c\> & \[d\]\(e\)\!_`,
+			"MarkdownV2",
+		},
+		{
+			m.TelegramAlert{ParseMode: "html", Message: "<b>{{.MonitorName}}</b> {{range .FailedTargets}}{{.}}{{end}}<pre>{{.LastCheckOutput}}</pre>"},
+			"<b>web_app</b> <pre>a*b &lt;c&gt; &amp; [d](e)!</pre>",
+			"HTML",
+		},
+		{
+			m.TelegramAlert{ParseMode: "MarkdownV2"},
+			"*web\\_app is down*\nFailures: 2\nLast success: Mon, 01 Jan 0001 00:00:00 UTC\n```\na\\*b <c\\> & \\[d\\]\\(e\\)\\!\n```",
+			"MarkdownV2 default",
+		},
+	}
+
+	for _, c := range cases {
+		alert := m.Alert{Name: "telegram", Telegram: &c.telegram}
+		alert.Telegram.URL = server.URL
+		alert.Telegram.BotToken = "123:abc"
+		alert.Telegram.ChatIDs = []string{"42", "@channel"}
+
+		if err := alert.BuildTemplates(); err != nil {
+			t.Fatalf("BuildTemplates(%v), unexpected error: %v", c.name, err)
+		}
+
+		if _, err := alert.Send(notice); err != nil {
+			t.Fatalf("Send(%v), unexpected error: %v", c.name, err)
+		}
+
+		for _, chatID := range alert.Telegram.ChatIDs {
+			request := <-requests
+			if request.path != "/bot123:abc/sendMessage" || request.message.ChatID != chatID {
+				t.Errorf("Send(%v), unexpected path=%q chat=%q", c.name, request.path, request.message.ChatID)
+			}
+
+			if request.message.Text != c.expected {
+				t.Errorf("Send(%v), expected text=%q actual=%q", c.name, c.expected, request.message.Text)
+			}
+		}
+	}
+}
+
+func TestTelegramAlertSendFailedRedactsToken(t *testing.T) {
+	t.Parallel()
+
+	alert := m.Alert{Name: "telegram", Telegram: &m.TelegramAlert{
+		URL:      "http://127.0.0.1:1",
+		BotToken: "123:secret",
+		ChatIDs:  []string{"42"},
+	}}
+
+	if err := alert.BuildTemplates(); err != nil {
+		t.Fatalf("BuildTemplates(), unexpected error: %v", err)
+	}
+
+	_, err := alert.Send(m.AlertNotice{MonitorName: "web"})
+	if !errors.Is(err, m.ErrAlertFailed) {
+		t.Errorf("Send(), expected=%v actual=%v", m.ErrAlertFailed, err)
+	}
+
+	if err != nil && strings.Contains(err.Error(), "secret") {
+		t.Errorf("Send(), expected bot token to be redacted from error: %v", err)
+	}
+}
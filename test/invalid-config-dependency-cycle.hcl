@@ -0,0 +1,23 @@
+check_interval = "1s"
+
+monitor "Router" {
+  command = ["echo"]
+  alert_down = ["log"]
+  depends_on = ["Server"]
+}
+
+monitor "Switch" {
+  command = ["echo"]
+  alert_down = ["log"]
+  depends_on = ["Router"]
+}
+
+monitor "Server" {
+  command = ["echo"]
+  alert_down = ["log"]
+  depends_on = ["Switch"]
+}
+
+alert "log" {
+  command = ["true"]
+}
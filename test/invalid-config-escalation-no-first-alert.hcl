@@ -0,0 +1,17 @@
+check_interval = "1s"
+
+escalation_policy "oncall" {
+  step {
+    after_alerts = 3
+    alerts = ["sms"]
+  }
+}
+
+monitor "Database" {
+  command = ["echo", "database"]
+  escalation_policy = "oncall"
+}
+
+alert "sms" {
+  command = ["echo", "sms", "{{.MonitorName}}"]
+}
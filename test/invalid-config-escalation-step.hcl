@@ -0,0 +1,19 @@
+check_interval = "1s"
+
+escalation_policy "oncall" {
+  step {
+    after_alerts = 3
+    after = "30m"
+    alerts = ["sms"]
+  }
+}
+
+monitor "Database" {
+  command = ["echo", "database"]
+  alert_down = ["chat"]
+  escalation_policy = "oncall"
+}
+
+alert "chat" {
+  command = ["echo", "chat", "{{.MonitorName}}"]
+}
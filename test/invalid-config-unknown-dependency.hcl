@@ -0,0 +1,11 @@
+check_interval = "1s"
+
+monitor "Child" {
+  command = ["echo"]
+  alert_down = ["log"]
+  depends_on = ["Missing"]
+}
+
+alert "log" {
+  command = ["true"]
+}
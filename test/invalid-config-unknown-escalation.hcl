@@ -0,0 +1,11 @@
+check_interval = "1s"
+
+monitor "Database" {
+  command = ["echo", "database"]
+  alert_down = ["chat"]
+  escalation_policy = "oncall"
+}
+
+alert "chat" {
+  command = ["echo", "chat", "{{.MonitorName}}"]
+}
@@ -2,6 +2,7 @@ check_interval = "1s"
 default_alert_down = ["log_command"]
 default_alert_every = 0
 default_alert_after = 2
+default_recover_after = 3
 
 monitor "Default" {
   command = ["echo"]
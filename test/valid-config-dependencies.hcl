@@ -0,0 +1,22 @@
+check_interval = "1s"
+
+monitor "Server" {
+  command = ["echo"]
+  alert_down = ["log"]
+  depends_on = ["Switch"]
+}
+
+monitor "Switch" {
+  command = ["echo"]
+  alert_down = ["log"]
+  depends_on = ["Router"]
+}
+
+monitor "Router" {
+  command = ["echo"]
+  alert_down = ["log"]
+}
+
+alert "log" {
+  command = ["true"]
+}
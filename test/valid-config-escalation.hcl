@@ -0,0 +1,49 @@
+check_interval = "1s"
+
+escalation_policy "oncall" {
+  step {
+    after_alerts = 3
+    alerts = ["sms"]
+  }
+
+  step {
+    after = "30m"
+    alerts = ["team_lead"]
+  }
+}
+
+monitor "Database" {
+  command = ["echo", "database"]
+  alert_down = ["chat"]
+  alert_every = 1
+  escalation_policy = "oncall"
+}
+
+monitor "Website" {
+  command = ["echo", "website"]
+  alert_every = 1
+
+  escalation {
+    step {
+      after_alerts = 1
+      alerts = ["chat"]
+    }
+
+    step {
+      after_alerts = 5
+      alerts = ["team_lead"]
+    }
+  }
+}
+
+alert "chat" {
+  command = ["echo", "chat", "{{.MonitorName}}"]
+}
+
+alert "sms" {
+  command = ["echo", "sms", "{{.MonitorName}}"]
+}
+
+alert "team_lead" {
+  command = ["echo", "team_lead", "{{.MonitorName}}"]
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ErrInvalidWebhook indicates that a webhook alert is not properly configured
+var ErrInvalidWebhook = errors.New("Invalid webhook configuration")
+
+const (
+	// alertHTTPTimeout limits how long built in alerts wait for a response
+	alertHTTPTimeout = 10 * time.Second
+	// defaultWebhookBody sends the whole notice when no body template is configured
+	defaultWebhookBody = "{{toJson .}}"
+)
+
+// alertHTTPClient is shared by all built in alerts that send http requests
+var alertHTTPClient = &http.Client{Timeout: alertHTTPTimeout}
+
+// WebhookAlert is a built in alert type that sends an http request with a templated body
+type WebhookAlert struct {
+	URL     string            `hcl:"url"`
+	Method  string            `hcl:"method,optional"`
+	Headers map[string]string `hcl:"headers,optional"`
+	Body    string            `hcl:"body,optional"`
+
+	bodyTemplate *template.Template
+}
+
+// Validate checks that the WebhookAlert is properly configured and returns errors if not
+func (webhook WebhookAlert) Validate() error {
+	var err error
+
+	err = errors.Join(err, validateHTTPURL(ErrInvalidWebhook, "url", webhook.URL))
+
+	if !slices.Contains([]string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodGet}, webhook.method()) {
+		err = errors.Join(err, fmt.Errorf("%w: unsupported method %s", ErrInvalidWebhook, webhook.Method))
+	}
+
+	return err
+}
+
+// BuildTemplates compiles the body template for the WebhookAlert
+func (webhook *WebhookAlert) BuildTemplates(name string, funcs template.FuncMap) error {
+	body := webhook.Body
+	if body == "" {
+		body = defaultWebhookBody
+	}
+
+	var err error
+
+	webhook.bodyTemplate, err = template.New(name + "-webhook").Funcs(funcs).Parse(body)
+
+	return err
+}
+
+// Send renders the body and sends it to the webhook url
+func (webhook WebhookAlert) Send(notice AlertNotice) (string, error) {
+	if webhook.bodyTemplate == nil {
+		return "", fmt.Errorf("No templates compiled for webhook: %w", errNoTemplate)
+	}
+
+	var body bytes.Buffer
+	if err := webhook.bodyTemplate.Execute(&body, notice); err != nil {
+		return "", err
+	}
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	for key, value := range webhook.Headers {
+		headers[http.CanonicalHeaderKey(key)] = value
+	}
+
+	return sendHTTPRequest(webhook.method(), webhook.URL, headers, body.Bytes())
+}
+
+func (webhook WebhookAlert) method() string {
+	if webhook.Method == "" {
+		return http.MethodPost
+	}
+
+	return strings.ToUpper(webhook.Method)
+}
+
+// postJSON encodes payload as json and posts it to the url
+func postJSON(requestURL string, headers map[string]string, payload any) (string, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to encode payload: %w", ErrAlertFailed, err)
+	}
+
+	jsonHeaders := map[string]string{"Content-Type": "application/json"}
+	for key, value := range headers {
+		jsonHeaders[key] = value
+	}
+
+	return sendHTTPRequest(http.MethodPost, requestURL, jsonHeaders, body)
+}
+
+// validateHTTPURL returns an error wrapping errType if value is not an absolute http or https url
+func validateHTTPURL(errType error, key, value string) error {
+	if value == "" {
+		return fmt.Errorf("%w: %s is required", errType, key)
+	}
+
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%w: %s must be an http or https url", errType, key)
+	}
+
+	return nil
+}
+
+// doHTTPRequest sends a request and returns the response status, headers and body
+func doHTTPRequest(method, requestURL string, headers map[string]string, body []byte) (*http.Response, string, error) {
+	req, err := http.NewRequest(method, requestURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: failed to build request: %w", ErrAlertFailed, err)
+	}
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := alertHTTPClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrAlertFailed, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp, "", fmt.Errorf("%w: failed to read response: %w", ErrAlertFailed, err)
+	}
+
+	return resp, string(respBody), nil
+}
+
+// sendHTTPRequest sends a request and returns the response body. Responses outside of the 2xx
+// range are returned as ErrAlertFailed
+func sendHTTPRequest(method, requestURL string, headers map[string]string, body []byte) (string, error) {
+	resp, respBody, err := doHTTPRequest(method, requestURL, headers, body)
+	if err != nil {
+		return respBody, err
+	}
+
+	return respBody, checkHTTPStatus(resp)
+}
+
+// checkHTTPStatus returns ErrAlertFailed if the response status is outside of the 2xx range
+func checkHTTPStatus(resp *http.Response) error {
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: unexpected response status %s", ErrAlertFailed, resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package main_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	m "git.iamthefij.com/iamthefij/minitor-go/v2"
+)
+
+// webhookRequest captures a request received by a test webhook server
+type webhookRequest struct {
+	method string
+	header http.Header
+	body   string
+}
+
+// newWebhookServer starts a server that records requests and responds with the given status
+func newWebhookServer(t *testing.T, status int) (*httptest.Server, chan webhookRequest) {
+	t.Helper()
+
+	requests := make(chan webhookRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- webhookRequest{method: r.Method, header: r.Header, body: string(body)}
+
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, requests
+}
+
+func TestWebhookAlertValidate(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		webhook  m.WebhookAlert
+		expected error
+		name     string
+	}{
+		{m.WebhookAlert{URL: "https://example.com/hook"}, nil, "Valid"},
+		{m.WebhookAlert{URL: "https://example.com/hook", Method: "put"}, nil, "Valid lowercase method"},
+		{m.WebhookAlert{}, m.ErrInvalidWebhook, "No url"},
+		{m.WebhookAlert{URL: "example.com/hook"}, m.ErrInvalidWebhook, "Relative url"},
+		{m.WebhookAlert{URL: "https://example.com/hook", Method: "DELETE"}, m.ErrInvalidWebhook, "Unsupported method"},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := c.webhook.Validate()
+			hasErr := (actual != nil)
+			expectErr := (c.expected != nil)
+
+			if hasErr != expectErr || !errors.Is(actual, c.expected) {
+				t.Errorf("Validate(%v), expected=%v actual=%v", c.name, c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestWebhookAlertSend(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newWebhookServer(t, http.StatusOK)
+	notice := m.AlertNotice{MonitorName: "web", LastCheckOutput: `said "no" \ quit`}
+
+	alert := m.Alert{
+		Name: "webhook",
+		Webhook: &m.WebhookAlert{
+			URL:     server.URL,
+			Method:  "put",
+			Headers: map[string]string{"Authorization": "Bearer secret"},
+			Body:    `{"text": {{toJson .MonitorName}}, "output": {{toJson .LastCheckOutput}}}`,
+		},
+	}
+
+	if err := alert.Validate(); err != nil {
+		t.Fatalf("Validate(), unexpected error: %v", err)
+	}
+
+	if err := alert.BuildTemplates(); err != nil {
+		t.Fatalf("BuildTemplates(), unexpected error: %v", err)
+	}
+
+	output, err := alert.Send(notice)
+	if err != nil {
+		t.Fatalf("Send(), unexpected error: %v", err)
+	}
+
+	if output != "ok" {
+		t.Errorf("Send(), expected response body as output, got %q", output)
+	}
+
+	request := <-requests
+	if request.method != http.MethodPut || request.header.Get("Authorization") != "Bearer secret" ||
+		request.header.Get("Content-Type") != "application/json" {
+		t.Errorf("Send(), unexpected request method=%s header=%v", request.method, request.header)
+	}
+
+	var body map[string]string
+	if err = json.Unmarshal([]byte(request.body), &body); err != nil {
+		t.Fatalf("Send(), expected a valid json body, got %q: %v", request.body, err)
+	}
+
+	if body["text"] != notice.MonitorName || body["output"] != notice.LastCheckOutput {
+		t.Errorf("Send(), unexpected body %v", body)
+	}
+}
+
+func TestWebhookAlertDefaultBody(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newWebhookServer(t, http.StatusNoContent)
+	alert := m.Alert{Name: "webhook", Webhook: &m.WebhookAlert{URL: server.URL}}
+
+	if err := alert.BuildTemplates(); err != nil {
+		t.Fatalf("BuildTemplates(), unexpected error: %v", err)
+	}
+
+	if _, err := alert.Send(m.AlertNotice{MonitorName: "web", FailureCount: 2}); err != nil {
+		t.Fatalf("Send(), unexpected error: %v", err)
+	}
+
+	var notice m.AlertNotice
+
+	request := <-requests
+	if err := json.Unmarshal([]byte(request.body), &notice); err != nil {
+		t.Fatalf("Send(), expected the notice as json, got %q: %v", request.body, err)
+	}
+
+	if request.method != http.MethodPost || notice.MonitorName != "web" || notice.FailureCount != 2 {
+		t.Errorf("Send(), unexpected request method=%s notice=%v", request.method, notice)
+	}
+}
+
+func TestWebhookAlertFailure(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newWebhookServer(t, http.StatusInternalServerError)
+	alert := m.Alert{Name: "webhook", Webhook: &m.WebhookAlert{URL: server.URL}}
+
+	if err := alert.BuildTemplates(); err != nil {
+		t.Fatalf("BuildTemplates(), unexpected error: %v", err)
+	}
+
+	if _, err := alert.Send(m.AlertNotice{MonitorName: "web"}); !errors.Is(err, m.ErrAlertFailed) {
+		t.Errorf("Send(), expected=%v actual=%v", m.ErrAlertFailed, err)
+	}
+}